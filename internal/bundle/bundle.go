@@ -0,0 +1,42 @@
+package bundle
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Options control how a bundle is built. Sources are named in source maps by
+// their path relative to Root, imports starting with / are resolved from it.
+type Options struct {
+	Root   string
+	Minify bool
+}
+
+// Result is a bundled file and the source map of its lines.
+type Result struct {
+	Content   string
+	SourceMap *SourceMap
+}
+
+func newResult(out *output, entry string) *Result {
+	return &Result{
+		Content:   out.sb.String(),
+		SourceMap: out.sourceMap(filepath.Base(entry)),
+	}
+}
+
+// readSource reads a file to bundle and names it relative to the root
+func readSource(file string, root string) (string, string, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read %s: %w", file, err)
+	}
+
+	name := file
+	if rel, err := filepath.Rel(root, file); err == nil && !strings.HasPrefix(rel, "..") {
+		name = filepath.ToSlash(rel)
+	}
+	return strings.ReplaceAll(string(content), "\r\n", "\n"), name, nil
+}
@@ -0,0 +1,41 @@
+package bundle
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestReadSource(t *testing.T) {
+	root := writeFiles(t, map[string]string{"css/site.css": "a {}\r\nb {}\r\n"})
+
+	content, name, err := readSource(filepath.Join(root, "css", "site.css"), root)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if name != "css/site.css" {
+		t.Errorf("expected name css/site.css, got %s", name)
+	}
+	if content != "a {}\nb {}\n" {
+		t.Errorf("expected line endings to be normalised, got %q", content)
+	}
+
+	if _, _, err := readSource(filepath.Join(root, "missing.css"), root); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
@@ -0,0 +1,107 @@
+package bundle
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/eastcitysoftware/ditto/internal/minify"
+)
+
+var cssImportPattern = regexp.MustCompile(`(?m)^[ \t]*@import\s+(?:url\(\s*)?["']?([^"')\s;]+)["']?\s*\)?\s*([^;]*);[ \t]*\n?`)
+
+// CSS bundles a stylesheet with the local stylesheets it imports, each is
+// included once where it is first imported. Imports with a media query are
+// wrapped in a media block and remote imports are moved to the top.
+func CSS(entry string, options Options) (*Result, error) {
+	b := &cssBundler{options: options, out: newOutput(), included: map[string]bool{}}
+	if err := b.include(entry, nil); err != nil {
+		return nil, err
+	}
+
+	// remote imports must come before any other rule
+	if len(b.remote) > 0 {
+		b.out.prepend(strings.Join(b.remote, "\n"))
+	}
+	return newResult(b.out, entry), nil
+}
+
+type cssBundler struct {
+	options  Options
+	out      *output
+	included map[string]bool
+	remote   []string
+}
+
+func (b *cssBundler) include(file string, stack []string) error {
+	for _, parent := range stack {
+		if parent == file {
+			return fmt.Errorf("import cycle: %s", strings.Join(append(stack, file), " -> "))
+		}
+	}
+	if b.included[file] {
+		return nil
+	}
+	b.included[file] = true
+
+	content, name, err := readSource(file, b.options.Root)
+	if err != nil {
+		return err
+	}
+	source := b.out.addSource(name, content)
+
+	offset := 0
+	for _, match := range cssImportPattern.FindAllStringSubmatchIndex(content, -1) {
+		b.writeCSS(content[offset:match[0]], source, lineAt(content, offset))
+		offset = match[1]
+
+		path, media := content[match[2]:match[3]], strings.TrimSpace(content[match[4]:match[5]])
+		if isRemote(path) {
+			b.remote = append(b.remote, strings.TrimSpace(content[match[0]:match[1]]))
+			continue
+		}
+
+		if media != "" {
+			b.out.write("@media "+media+"{", -1, nil)
+		}
+		imported := filepath.Join(filepath.Dir(file), filepath.FromSlash(path))
+		if strings.HasPrefix(path, "/") {
+			imported = filepath.Join(b.options.Root, filepath.FromSlash(path))
+		}
+		if err := b.include(imported, append(stack, file)); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if media != "" {
+			b.out.write("}", -1, nil)
+		}
+	}
+	b.writeCSS(content[offset:], source, lineAt(content, offset))
+	return nil
+}
+
+// writeCSS adds a part of a stylesheet, minified parts are written on a
+// single line that maps to the start of the part
+func (b *cssBundler) writeCSS(text string, source int, firstLine int) {
+	if !b.options.Minify {
+		b.out.writeFrom(text, source, firstLine)
+		return
+	}
+
+	// the mapping starts at the first line with content
+	trimmed := strings.TrimLeft(text, " \t\r\n")
+	firstLine += strings.Count(text[:len(text)-len(trimmed)], "\n")
+	if minified := minify.CSS(trimmed); minified != "" {
+		b.out.write(minified, source, []int{firstLine})
+	}
+}
+
+func isRemote(path string) bool {
+	return strings.HasPrefix(path, "http:") || strings.HasPrefix(path, "https:") ||
+		strings.HasPrefix(path, "//") || strings.HasPrefix(path, "data:")
+}
+
+// lineAt returns the 1-based line of an offset
+func lineAt(content string, offset int) int {
+	return strings.Count(content[:offset], "\n") + 1
+}
@@ -0,0 +1,75 @@
+package bundle
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCSS(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"css/site.css": `@import "https://fonts.example.com/font.css";
+@import "base.css";
+@import url("print.css") print;
+@import "base.css";
+body { color: red; }
+`,
+		"css/base.css":  "@import \"/css/reset.css\";\nhtml { margin: 0; }\n",
+		"css/reset.css": "* { box-sizing: border-box; }\n",
+		"css/print.css": "nav { display: none; }\n",
+	})
+
+	result, err := CSS(filepath.Join(root, "css", "site.css"), Options{Root: root})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := `@import "https://fonts.example.com/font.css";
+* { box-sizing: border-box; }
+html { margin: 0; }
+@media print{
+nav { display: none; }
+}
+body { color: red; }
+`
+	if result.Content != expected {
+		t.Errorf("expected %q, got %q", expected, result.Content)
+	}
+
+	sources := strings.Join(result.SourceMap.Sources, ",")
+	if sources != "css/site.css,css/base.css,css/reset.css,css/print.css" {
+		t.Errorf("unexpected sources %s", sources)
+	}
+}
+
+func TestCSSMinify(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"site.css": "@import \"base.css\";\n\nbody {\n  color: red;\n}\n",
+		"base.css": "/* base */\nhtml {\n  margin: 0;\n}\n",
+	})
+
+	result, err := CSS(filepath.Join(root, "site.css"), Options{Root: root, Minify: true})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Content != "html{margin:0}\nbody{color:red}\n" {
+		t.Errorf("unexpected minified bundle %q", result.Content)
+	}
+
+	// each minified part maps to the first line of its source part
+	if result.SourceMap.Mappings != "ACAA;ADEA" {
+		t.Errorf("unexpected mappings %s", result.SourceMap.Mappings)
+	}
+}
+
+func TestCSSImportCycle(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"a.css": "@import \"b.css\";\n",
+		"b.css": "@import \"a.css\";\n",
+	})
+
+	_, err := CSS(filepath.Join(root, "a.css"), Options{Root: root})
+	if err == nil || !strings.Contains(err.Error(), "import cycle") {
+		t.Errorf("expected an import cycle error, got %v", err)
+	}
+}
@@ -0,0 +1,224 @@
+package bundle
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/eastcitysoftware/ditto/internal/minify"
+)
+
+const (
+	requireFunc = "__ditto_require"
+	exportsVar  = "__exports"
+)
+
+var (
+	jsImportFromPattern = regexp.MustCompile(`(?m)^[ \t]*import\s+([\w$*{}\s,]+?)\s+from\s+["']([^"']+)["'][ \t]*;?`)
+	jsImportPattern     = regexp.MustCompile(`(?m)^[ \t]*import\s+["']([^"']+)["'][ \t]*;?`)
+	jsExportFromPattern = regexp.MustCompile(`(?m)^[ \t]*export\s+(\*(?:\s+as\s+[\w$]+)?|\{[^}]*\})\s+from\s+["']([^"']+)["'][ \t]*;?`)
+	jsExportListPattern = regexp.MustCompile(`(?m)^[ \t]*export\s*\{([^}]*)\}[ \t]*;?`)
+	jsExportDeclPattern = regexp.MustCompile(`(?m)^([ \t]*)export\s+(default\s+)?((?:async\s+)?function\s*\*?|class|const|let|var)\s+([\w$]+)`)
+	jsExportDefault     = regexp.MustCompile(`(?m)^([ \t]*)export\s+default\s+`)
+)
+
+// JS bundles an es module with the local modules it imports. Each module is
+// wrapped in a function that is run once, the first time it is imported,
+// and its exports are read through getters. Imports are resolved relative
+// to the importing module and must name local files.
+func JS(entry string, options Options) (*Result, error) {
+	b := &jsBundler{options: options, out: newOutput(), ids: map[string]string{}}
+	b.out.write("(() => {\n"+
+		"const modules = {}, cache = {};\n"+
+		"const "+requireFunc+" = (id) => {\n"+
+		"if (!cache[id]) { cache[id] = {}; modules[id](cache[id]); }\n"+
+		"return cache[id];\n"+
+		"};", -1, nil)
+
+	id, err := b.include(entry)
+	if err != nil {
+		return nil, err
+	}
+	b.out.write(requireFunc+"("+strconv.Quote(id)+");\n})();", -1, nil)
+	return newResult(b.out, entry), nil
+}
+
+type jsBundler struct {
+	options Options
+	out     *output
+	ids     map[string]string
+}
+
+// include adds a module and the modules it imports, returning its id
+func (b *jsBundler) include(file string) (string, error) {
+	if id, ok := b.ids[file]; ok {
+		return id, nil
+	}
+
+	content, name, err := readSource(file, b.options.Root)
+	if err != nil {
+		return "", err
+	}
+	b.ids[file] = name
+	source := b.out.addSource(name, content)
+
+	// imports are resolved first so that the module can be written whole
+	resolveErr := error(nil)
+	resolve := func(spec string) string {
+		imported, err := b.resolve(file, spec)
+		if err == nil {
+			spec, err = b.include(imported)
+		}
+		if err != nil && resolveErr == nil {
+			resolveErr = fmt.Errorf("%s: %w", name, err)
+		}
+		return spec
+	}
+	code, getters := transformModule(content, resolve)
+	if resolveErr != nil {
+		return "", resolveErr
+	}
+
+	b.out.write("modules["+strconv.Quote(name)+"] = ("+exportsVar+") => {"+getters, -1, nil)
+	if b.options.Minify {
+		minified, lines := minify.JSLines(code)
+		b.out.write(minified, source, lines)
+	} else {
+		b.out.writeFrom(code, source, 1)
+	}
+	b.out.write("};", -1, nil)
+	return name, nil
+}
+
+func (b *jsBundler) resolve(file string, spec string) (string, error) {
+	var resolved string
+	switch {
+	case strings.HasPrefix(spec, "./") || strings.HasPrefix(spec, "../"):
+		resolved = filepath.Join(filepath.Dir(file), filepath.FromSlash(spec))
+	case strings.HasPrefix(spec, "/"):
+		resolved = filepath.Join(b.options.Root, filepath.FromSlash(spec))
+	default:
+		return "", fmt.Errorf("cannot bundle import %q, only local files can be imported", spec)
+	}
+
+	for _, candidate := range []string{resolved, resolved + ".js", resolved + ".mjs", filepath.Join(resolved, "index.js")} {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("import %q not found", spec)
+}
+
+// transformModule rewrites the import and export statements of a module
+// into calls to the bundle require func and getters on the exports object.
+// Statements keep their number of lines so the source map stays line exact.
+func transformModule(content string, resolve func(string) string) (string, string) {
+	getters := []string{}
+	addGetter := func(name string, value string) {
+		getters = append(getters, fmt.Sprintf("Object.defineProperty(%s, %s, {enumerable: true, get: () => %s});",
+			exportsVar, strconv.Quote(name), value))
+	}
+	keepLines := func(statement string, replacement string) string {
+		return replacement + strings.Repeat("\n", strings.Count(statement, "\n"))
+	}
+
+	content = jsExportFromPattern.ReplaceAllStringFunc(content, func(statement string) string {
+		match := jsExportFromPattern.FindStringSubmatch(statement)
+		module := requireFunc + "(" + strconv.Quote(resolve(match[2])) + ")"
+		if strings.HasPrefix(match[1], "*") {
+			if _, alias, ok := strings.Cut(match[1], " as "); ok {
+				addGetter(strings.TrimSpace(alias), module)
+				return keepLines(statement, "")
+			}
+			return keepLines(statement, fmt.Sprintf("Object.keys(%[1]s).forEach((k) => { if (k !== \"default\" && !(k in %[2]s)) "+
+				"Object.defineProperty(%[2]s, k, {enumerable: true, get: () => %[1]s[k]}); });", module, exportsVar))
+		}
+		for _, s := range parseSpecifiers(match[1]) {
+			addGetter(s.alias, module+"["+strconv.Quote(s.name)+"]")
+		}
+		return keepLines(statement, "")
+	})
+
+	content = jsImportFromPattern.ReplaceAllStringFunc(content, func(statement string) string {
+		match := jsImportFromPattern.FindStringSubmatch(statement)
+		module := requireFunc + "(" + strconv.Quote(resolve(match[2])) + ")"
+		return keepLines(statement, importBindings(strings.TrimSpace(match[1]), module))
+	})
+
+	content = jsImportPattern.ReplaceAllStringFunc(content, func(statement string) string {
+		match := jsImportPattern.FindStringSubmatch(statement)
+		return keepLines(statement, requireFunc+"("+strconv.Quote(resolve(match[1]))+");")
+	})
+
+	content = jsExportListPattern.ReplaceAllStringFunc(content, func(statement string) string {
+		match := jsExportListPattern.FindStringSubmatch(statement)
+		for _, s := range parseSpecifiers(match[1]) {
+			addGetter(s.alias, s.name)
+		}
+		return keepLines(statement, "")
+	})
+
+	content = jsExportDeclPattern.ReplaceAllStringFunc(content, func(statement string) string {
+		match := jsExportDeclPattern.FindStringSubmatch(statement)
+		exported := match[4]
+		if match[2] != "" {
+			exported = "default"
+		}
+		addGetter(exported, match[4])
+		return match[1] + match[3] + " " + match[4]
+	})
+
+	content = jsExportDefault.ReplaceAllString(content, "${1}"+exportsVar+".default = ")
+	return content, strings.Join(getters, " ")
+}
+
+// importBindings declares the names bound by an import clause, like
+// "x, { a, b as c }" or "* as ns"
+func importBindings(clause string, module string) string {
+	declarations := []string{}
+	if defaultName, rest, _ := strings.Cut(clause, ","); !strings.HasPrefix(clause, "{") && !strings.HasPrefix(clause, "*") {
+		declarations = append(declarations, fmt.Sprintf("const %s = %s.default;", strings.TrimSpace(defaultName), module))
+		clause = strings.TrimSpace(rest)
+	}
+
+	if namespace, ok := strings.CutPrefix(clause, "*"); ok {
+		_, name, _ := strings.Cut(namespace, "as")
+		declarations = append(declarations, fmt.Sprintf("const %s = %s;", strings.TrimSpace(name), module))
+	} else if clause != "" {
+		bindings := []string{}
+		for _, s := range parseSpecifiers(clause) {
+			if s.name == s.alias {
+				bindings = append(bindings, s.name)
+			} else {
+				bindings = append(bindings, s.name+": "+s.alias)
+			}
+		}
+		declarations = append(declarations, fmt.Sprintf("const { %s } = %s;", strings.Join(bindings, ", "), module))
+	}
+	return strings.Join(declarations, " ")
+}
+
+// specifier is a name in an import or export list and the name it is
+// bound to, as in "a as b"
+type specifier struct {
+	name  string
+	alias string
+}
+
+// parseSpecifiers reads a list like "{ a, b as c }" in the order it is written
+func parseSpecifiers(list string) []specifier {
+	specifiers := []specifier{}
+	for _, item := range strings.Split(strings.Trim(strings.TrimSpace(list), "{}"), ",") {
+		fields := strings.Fields(item)
+		switch {
+		case len(fields) == 1:
+			specifiers = append(specifiers, specifier{name: fields[0], alias: fields[0]})
+		case len(fields) == 3 && fields[1] == "as":
+			specifiers = append(specifiers, specifier{name: fields[0], alias: fields[2]})
+		}
+	}
+	return specifiers
+}
@@ -0,0 +1,101 @@
+package bundle
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestJS(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"js/main.js": `import greet, { name as who } from "./greet";
+import * as math from "./lib/index.js";
+import "./side.js";
+console.log(greet(who), math.add(1, 2));
+`,
+		"js/greet.js":     "export const name = \"world\";\nexport default function greet(who) {\n  return \"hello \" + who;\n}\n",
+		"js/lib/index.js": "export function add(a, b) { return a + b; }\nexport { add as sum };\nexport * from \"/js/lib/extra.js\";\n",
+		"js/lib/extra.js": "export let extra = 1;\n",
+		"js/side.js":      "console.log(\"side\");\n",
+	})
+
+	result, err := JS(filepath.Join(root, "js", "main.js"), Options{Root: root})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for _, expected := range []string{
+		`modules["js/greet.js"] = (__exports) => {Object.defineProperty(__exports, "name", {enumerable: true, get: () => name}); ` +
+			`Object.defineProperty(__exports, "default", {enumerable: true, get: () => greet});`,
+		"\nfunction greet(who) {\n",
+		`const greet = __ditto_require("js/greet.js").default; const { name: who } = __ditto_require("js/greet.js");`,
+		`const math = __ditto_require("js/lib/index.js");`,
+		`Object.defineProperty(__exports, "sum", {enumerable: true, get: () => add});`,
+		`Object.keys(__ditto_require("js/lib/extra.js"))`,
+		"\n__ditto_require(\"js/side.js\");\n",
+		"\n__ditto_require(\"js/main.js\");\n})();\n",
+	} {
+		if !strings.Contains(result.Content, expected) {
+			t.Errorf("expected bundle to contain %q, got %s", expected, result.Content)
+		}
+	}
+
+	// modules are written once, before the modules that import them
+	if strings.Count(result.Content, `modules["js/greet.js"] =`) != 1 ||
+		strings.Index(result.Content, `modules["js/greet.js"] =`) > strings.Index(result.Content, `modules["js/main.js"] =`) {
+		t.Errorf("expected each module once in import order, got %s", result.Content)
+	}
+}
+
+func TestJSKeepsLines(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"main.js": "import {\n  a,\n  b\n} from \"./ab.js\";\nconsole.log(a, b);\n",
+		"ab.js":   "export const a = 1, b = 2;\n",
+	})
+
+	result, err := JS(filepath.Join(root, "main.js"), Options{Root: root})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// the console.log call is on line 5 of main.js
+	lines := strings.Split(result.Content, "\n")
+	mappings := strings.Split(result.SourceMap.Mappings, ";")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "console.log") {
+			if mappings[i] == "" {
+				t.Fatalf("expected the call to be mapped")
+			}
+			return
+		}
+	}
+	t.Errorf("expected the call in the bundle, got %s", result.Content)
+}
+
+func TestJSMinify(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"main.js": "// entry\nconst  a = 1;\n\n  console.log(a);\n",
+	})
+
+	result, err := JS(filepath.Join(root, "main.js"), Options{Root: root, Minify: true})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(result.Content, "{\nconst a = 1;\nconsole.log(a);\n};") {
+		t.Errorf("expected the module to be minified, got %s", result.Content)
+	}
+}
+
+func TestJSImportErrors(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"bare.js":    "import x from \"lodash\";\n",
+		"missing.js": "import x from \"./nope.js\";\n",
+	})
+
+	if _, err := JS(filepath.Join(root, "bare.js"), Options{Root: root}); err == nil || !strings.Contains(err.Error(), "only local files") {
+		t.Errorf("expected an error for a bare import, got %v", err)
+	}
+	if _, err := JS(filepath.Join(root, "missing.js"), Options{Root: root}); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected an error for a missing import, got %v", err)
+	}
+}
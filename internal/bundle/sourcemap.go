@@ -0,0 +1,140 @@
+package bundle
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+const base64Chars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+// SourceMap is a version 3 source map. Bundles are mapped by line, each line
+// of the output points at the start of a line in one of the sources.
+type SourceMap struct {
+	Version        int      `json:"version"`
+	File           string   `json:"file"`
+	Sources        []string `json:"sources"`
+	SourcesContent []string `json:"sourcesContent"`
+	Names          []string `json:"names"`
+	Mappings       string   `json:"mappings"`
+}
+
+// JSON encodes the source map.
+func (m *SourceMap) JSON() ([]byte, error) {
+	return json.Marshal(m)
+}
+
+// lineMapping is the source of an output line, or a source of -1 for lines
+// added by the bundler
+type lineMapping struct {
+	source int
+	line   int
+}
+
+// output collects bundled text along with the source of every line
+type output struct {
+	sb       strings.Builder
+	lines    []lineMapping
+	sources  []string
+	contents []string
+	indexes  map[string]int
+}
+
+func newOutput() *output {
+	return &output{indexes: map[string]int{}}
+}
+
+func (o *output) addSource(name string, content string) int {
+	if index, ok := o.indexes[name]; ok {
+		return index
+	}
+	o.indexes[name] = len(o.sources)
+	o.sources = append(o.sources, name)
+	o.contents = append(o.contents, content)
+	return o.indexes[name]
+}
+
+// write adds lines of text, lines gives the 1-based source line of each
+// line of text, or is nil for text added by the bundler
+func (o *output) write(text string, source int, lines []int) {
+	if text == "" {
+		return
+	}
+	for i := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		mapping := lineMapping{source: -1}
+		if lines != nil && i < len(lines) {
+			mapping = lineMapping{source: source, line: lines[i]}
+		}
+		o.lines = append(o.lines, mapping)
+	}
+	o.sb.WriteString(strings.TrimSuffix(text, "\n"))
+	o.sb.WriteByte('\n')
+}
+
+// prepend adds lines of text from the bundler before the lines written so far
+func (o *output) prepend(text string) {
+	written := o.sb.String()
+	lines := o.lines
+	o.sb.Reset()
+	o.lines = nil
+	o.write(text, -1, nil)
+	o.sb.WriteString(written)
+	o.lines = append(o.lines, lines...)
+}
+
+// writeFrom adds lines of text from a source, starting at the line
+func (o *output) writeFrom(text string, source int, firstLine int) {
+	count := strings.Count(strings.TrimSuffix(text, "\n"), "\n") + 1
+	lines := make([]int, count)
+	for i := range lines {
+		lines[i] = firstLine + i
+	}
+	o.write(text, source, lines)
+}
+
+func (o *output) sourceMap(file string) *SourceMap {
+	var mappings strings.Builder
+	prevSource, prevLine := 0, 0
+	for i, line := range o.lines {
+		if i > 0 {
+			mappings.WriteByte(';')
+		}
+		if line.source < 0 {
+			continue
+		}
+		// output column, source index, source line and source column, each
+		// relative to the previous segment
+		writeVLQ(&mappings, 0)
+		writeVLQ(&mappings, line.source-prevSource)
+		writeVLQ(&mappings, line.line-1-prevLine)
+		writeVLQ(&mappings, 0)
+		prevSource, prevLine = line.source, line.line-1
+	}
+
+	return &SourceMap{
+		Version:        3,
+		File:           file,
+		Sources:        o.sources,
+		SourcesContent: o.contents,
+		Names:          []string{},
+		Mappings:       mappings.String(),
+	}
+}
+
+// writeVLQ writes a base64 variable length quantity as used by source maps
+func writeVLQ(sb *strings.Builder, value int) {
+	vlq := value << 1
+	if value < 0 {
+		vlq = (-value << 1) | 1
+	}
+	for {
+		digit := vlq & 31
+		vlq >>= 5
+		if vlq > 0 {
+			digit |= 32
+		}
+		sb.WriteByte(base64Chars[digit])
+		if vlq == 0 {
+			return
+		}
+	}
+}
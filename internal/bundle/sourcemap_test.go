@@ -0,0 +1,52 @@
+package bundle
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestWriteVLQ(t *testing.T) {
+	for value, expected := range map[int]string{0: "A", 1: "C", -1: "D", 15: "e", 16: "gB", -17: "jB", 1000: "w+B"} {
+		var sb strings.Builder
+		writeVLQ(&sb, value)
+		if sb.String() != expected {
+			t.Errorf("expected %d to encode as %s, got %s", value, expected, sb.String())
+		}
+	}
+}
+
+func TestSourceMap(t *testing.T) {
+	out := newOutput()
+	a := out.addSource("a.js", "one\ntwo\n")
+	b := out.addSource("b.js", "three\n")
+	if out.addSource("a.js", "") != a {
+		t.Error("expected a source to be added once")
+	}
+
+	out.write("// banner", -1, nil)
+	out.writeFrom("one\ntwo\n", a, 1)
+	out.writeFrom("three", b, 1)
+	out.prepend("// first")
+
+	if out.sb.String() != "// first\n// banner\none\ntwo\nthree\n" {
+		t.Errorf("unexpected output %q", out.sb.String())
+	}
+
+	m := out.sourceMap("out.js")
+	if m.Mappings != ";;AAAA;AACA;ACDA" {
+		t.Errorf("unexpected mappings %s", m.Mappings)
+	}
+
+	data, err := m.JSON()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("expected valid json, got %v", err)
+	}
+	if decoded["version"] != float64(3) || decoded["file"] != "out.js" {
+		t.Errorf("unexpected source map %s", data)
+	}
+}
@@ -0,0 +1,142 @@
+package check
+
+import (
+	"html"
+	"strings"
+)
+
+type htmlTag struct {
+	name  string
+	attrs map[string]string
+	line  int
+}
+
+// parseTags scans an html document for start tags and their attributes. It
+// is not a full html parser, comments and the contents of script and style
+// elements are skipped which is enough to find links and anchors.
+func parseTags(doc string) []htmlTag {
+	tags := []htmlTag{}
+	i := 0
+	for {
+		start := strings.IndexByte(doc[i:], '<')
+		if start == -1 {
+			return tags
+		}
+		i += start
+
+		if strings.HasPrefix(doc[i:], "<!--") {
+			end := strings.Index(doc[i+4:], "-->")
+			if end == -1 {
+				return tags
+			}
+			i += 4 + end + 3
+			continue
+		}
+
+		tag, next := parseTag(doc, i)
+		if tag == nil {
+			i++
+			continue
+		}
+		tag.line = strings.Count(doc[:i], "\n") + 1
+		tags = append(tags, *tag)
+		i = next
+
+		// raw text elements cannot contain tags
+		if tag.name == "script" || tag.name == "style" {
+			end := indexFold(doc[i:], "</"+tag.name)
+			if end == -1 {
+				return tags
+			}
+			i += end
+		}
+	}
+}
+
+// indexFold is strings.Index ignoring case, matching within s so that the
+// index is never taken from a lowercased copy of a different length
+func indexFold(s string, substr string) int {
+	for i := 0; i+len(substr) <= len(s); i++ {
+		if strings.EqualFold(s[i:i+len(substr)], substr) {
+			return i
+		}
+	}
+	return -1
+}
+
+func parseTag(doc string, start int) (*htmlTag, int) {
+	i := start + 1
+	nameStart := i
+	for i < len(doc) && isNameChar(doc[i]) {
+		i++
+	}
+	if i == nameStart {
+		return nil, start
+	}
+
+	tag := &htmlTag{name: strings.ToLower(doc[nameStart:i]), attrs: map[string]string{}}
+	for i < len(doc) {
+		i = skipSpace(doc, i)
+		if i >= len(doc) {
+			break
+		}
+		if doc[i] == '>' {
+			return tag, i + 1
+		}
+		if doc[i] == '/' {
+			i++
+			continue
+		}
+
+		attrStart := i
+		for i < len(doc) && !isSpace(doc[i]) && doc[i] != '=' && doc[i] != '>' && doc[i] != '/' {
+			i++
+		}
+		name := strings.ToLower(doc[attrStart:i])
+		if name == "" {
+			i++
+			continue
+		}
+
+		i = skipSpace(doc, i)
+		value := ""
+		if i < len(doc) && doc[i] == '=' {
+			i = skipSpace(doc, i+1)
+			if i < len(doc) && (doc[i] == '"' || doc[i] == '\'') {
+				quote := doc[i]
+				end := strings.IndexByte(doc[i+1:], quote)
+				if end == -1 {
+					return tag, len(doc)
+				}
+				value = doc[i+1 : i+1+end]
+				i += end + 2
+			} else {
+				valueStart := i
+				for i < len(doc) && !isSpace(doc[i]) && doc[i] != '>' {
+					i++
+				}
+				value = doc[valueStart:i]
+			}
+		}
+
+		if _, exists := tag.attrs[name]; !exists {
+			tag.attrs[name] = html.UnescapeString(value)
+		}
+	}
+	return tag, len(doc)
+}
+
+func skipSpace(doc string, i int) int {
+	for i < len(doc) && isSpace(doc[i]) {
+		i++
+	}
+	return i
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func isNameChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-'
+}
@@ -0,0 +1,199 @@
+package check
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// linkAttrs are the attributes holding urls that are checked
+var linkAttrs = []string{"href", "src", "srcset", "poster"}
+
+type BrokenLink struct {
+	Page     string
+	Template string
+	Line     int
+	Link     string
+	Reason   string
+}
+
+func (b BrokenLink) String() string {
+	source := b.Page
+	if b.Template != "" {
+		source = fmt.Sprintf("%s (%s)", b.Page, b.Template)
+	}
+	return fmt.Sprintf("%s:%d: %s %s", source, b.Line, b.Link, b.Reason)
+}
+
+type linkChecker struct {
+	outputDir string
+	anchors   map[string]map[string]bool
+}
+
+// CheckLinks parses every html file in outputDir and reports internal links
+// that do not resolve to a file, or whose fragment does not match an id in
+// the target page. Templates maps output paths relative to outputDir to the
+// page template that produced them, so broken links can be traced back.
+func CheckLinks(outputDir string, templates map[string]string) ([]BrokenLink, error) {
+	htmlFiles := []string{}
+	err := filepath.WalkDir(outputDir, func(file string, d os.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("failed to walk files in '%s': %w", outputDir, err)
+		}
+		if !d.IsDir() && isHTML(file) {
+			htmlFiles = append(htmlFiles, file)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	checker := &linkChecker{outputDir: outputDir, anchors: map[string]map[string]bool{}}
+	broken := []BrokenLink{}
+	for _, htmlFile := range htmlFiles {
+		rel, err := filepath.Rel(outputDir, htmlFile)
+		if err != nil {
+			return nil, err
+		}
+		rel = filepath.ToSlash(rel)
+
+		content, err := os.ReadFile(htmlFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", htmlFile, err)
+		}
+
+		for _, tag := range parseTags(string(content)) {
+			for _, link := range getLinks(tag) {
+				if reason := checker.check(rel, link); reason != "" {
+					broken = append(broken, BrokenLink{
+						Page:     "/" + rel,
+						Template: templates[rel],
+						Line:     tag.line,
+						Link:     link,
+						Reason:   reason})
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(broken, func(i, j int) bool {
+		return broken[i].Page < broken[j].Page
+	})
+	return broken, nil
+}
+
+func getLinks(tag htmlTag) []string {
+	links := []string{}
+	for _, attr := range linkAttrs {
+		value, ok := tag.attrs[attr]
+		if !ok {
+			continue
+		}
+		if attr != "srcset" {
+			links = append(links, strings.TrimSpace(value))
+			continue
+		}
+
+		// srcset candidates are "url [descriptor]" separated by commas
+		for _, candidate := range strings.Split(value, ",") {
+			if fields := strings.Fields(candidate); len(fields) > 0 {
+				links = append(links, fields[0])
+			}
+		}
+	}
+	return links
+}
+
+// check returns the reason a link from the page at rel is broken, or an
+// empty string if the link resolves.
+func (c *linkChecker) check(rel string, link string) string {
+	if link == "" {
+		return ""
+	}
+
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return "is not a valid url"
+	}
+	if parsed.Scheme != "" || parsed.Host != "" {
+		return ""
+	}
+
+	// resolve the path against the directory of the linking page
+	target := rel
+	if parsed.Path != "" {
+		if strings.HasPrefix(parsed.Path, "/") {
+			target = path.Clean(parsed.Path)
+		} else {
+			target = path.Join("/", path.Dir(rel), parsed.Path)
+		}
+		target = strings.TrimPrefix(target, "/")
+		if strings.HasSuffix(parsed.Path, "/") || target == "" {
+			target = path.Join(target, "index.html")
+		}
+
+		resolved, ok := c.resolve(target)
+		if !ok {
+			return "does not resolve to a page or asset"
+		}
+		target = resolved
+	}
+
+	// "#top" scrolls to the top of the page without a matching anchor
+	if parsed.Fragment == "" || parsed.Fragment == "top" || !isHTML(target) {
+		return ""
+	}
+	anchors, err := c.getAnchors(target)
+	if err != nil {
+		return fmt.Sprintf("could not be read: %v", err)
+	}
+	if !anchors[parsed.Fragment] {
+		return fmt.Sprintf("has no anchor #%s", parsed.Fragment)
+	}
+	return ""
+}
+
+func (c *linkChecker) resolve(target string) (string, bool) {
+	// try the file, then a pretty url directory, then an ugly url page
+	candidates := []string{target, path.Join(target, "index.html"), target + ".html"}
+	for _, candidate := range candidates {
+		info, err := os.Stat(filepath.Join(c.outputDir, filepath.FromSlash(candidate)))
+		if err == nil && !info.IsDir() {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+func (c *linkChecker) getAnchors(target string) (map[string]bool, error) {
+	if anchors, ok := c.anchors[target]; ok {
+		return anchors, nil
+	}
+
+	content, err := os.ReadFile(filepath.Join(c.outputDir, filepath.FromSlash(target)))
+	if err != nil {
+		return nil, err
+	}
+
+	anchors := map[string]bool{}
+	for _, tag := range parseTags(string(content)) {
+		if id, ok := tag.attrs["id"]; ok {
+			anchors[id] = true
+		}
+		if name, ok := tag.attrs["name"]; ok && tag.name == "a" {
+			anchors[name] = true
+		}
+	}
+	c.anchors[target] = anchors
+	return anchors, nil
+}
+
+func isHTML(file string) bool {
+	ext := strings.ToLower(path.Ext(file))
+	return ext == ".html" || ext == ".htm"
+}
@@ -0,0 +1,85 @@
+package check
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseTags(t *testing.T) {
+	doc := `<!-- <a href="/commented"> -->
+<a class=nav HREF='/about/?x=1&amp;y=2'>About</a>
+<script>if (a <b) { document.write("<a href='/script'>") }</script>
+<img src="/logo.png" alt="">`
+
+	tags := parseTags(doc)
+	if len(tags) != 3 {
+		t.Fatalf("expected 3 tags, got %d: %+v", len(tags), tags)
+	}
+	if tags[0].name != "a" || tags[0].attrs["href"] != "/about/?x=1&y=2" || tags[0].line != 2 {
+		t.Errorf("unexpected anchor tag %+v", tags[0])
+	}
+	if tags[1].name != "script" {
+		t.Errorf("expected script tag, got %+v", tags[1])
+	}
+	if tags[2].attrs["src"] != "/logo.png" || tags[2].line != 4 {
+		t.Errorf("unexpected image tag %+v", tags[2])
+	}
+}
+
+func TestParseTagsNonASCII(t *testing.T) {
+	// the kelvin sign is shorter once lowercased
+	doc := "<p>" + strings.Repeat("\u212a", 20) + "</p><SCRIPT>x = '<a href=\"/script\">'</Script><a href=\"/after\">"
+
+	tags := parseTags(doc)
+	if len(tags) != 3 || tags[2].attrs["href"] != "/after" {
+		t.Errorf("expected the link after the script, got %+v", tags)
+	}
+}
+
+func TestCheckLinks(t *testing.T) {
+	outputDir := t.TempDir()
+	files := map[string]string{
+		"index.html": `<a href="/about/">ok</a>
+<a href="about/#team">ok</a>
+<a href="/about/#missing">bad anchor</a>
+<a href="/contact/">bad page</a>
+<a href="https://example.com/nowhere">external</a>
+<a href="#top">top</a>
+<img srcset="/img/a.png 1x, /img/b.png 2x">`,
+		"about/index.html": `<h2 id="team">Team</h2><a href="../index.html">home</a><a href="/guide">ugly</a>`,
+		"guide.html":       ``,
+		"img/a.png":        ``,
+	}
+	for name, content := range files {
+		file := filepath.Join(outputDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+			t.Fatalf("failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	broken, err := CheckLinks(outputDir, map[string]string{"index.html": "pages/index.tmpl"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := []string{"/about/#missing", "/contact/", "/img/b.png"}
+	if len(broken) != len(expected) {
+		t.Fatalf("expected %d broken links, got %d: %v", len(expected), len(broken), broken)
+	}
+	for i, link := range broken {
+		if link.Link != expected[i] {
+			t.Errorf("expected broken link %s, got %s", expected[i], link.Link)
+		}
+		if link.Page != "/index.html" || link.Template != "pages/index.tmpl" {
+			t.Errorf("expected link to be reported for index page, got %+v", link)
+		}
+	}
+	if broken[0].Line != 3 {
+		t.Errorf("expected broken anchor on line 3, got %d", broken[0].Line)
+	}
+}
@@ -0,0 +1,402 @@
+package check
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"os"
+	"sort"
+	"strings"
+	"text/template/parse"
+
+	"github.com/eastcitysoftware/ditto/internal/render"
+	"github.com/eastcitysoftware/ditto/internal/website"
+)
+
+type TemplateIssue struct {
+	Location string
+	Message  string
+}
+
+func (i TemplateIssue) String() string {
+	return fmt.Sprintf("%s: %s", i.Location, i.Message)
+}
+
+// templateFile is a layout, partial or page parsed on its own so that
+// locations in its parse trees point at the file itself.
+type templateFile struct {
+	path       string
+	layout     string
+	partials   []*templateFile
+	shortcodes []templateRef
+	defines    map[string]bool
+	trees      []*parse.Tree
+}
+
+type templateRef struct {
+	name string
+	tree *parse.Tree
+	node parse.Node
+	at   string
+}
+
+// CheckTemplates parses the layouts, partials and pages of a loaded website
+// and reports references to undefined templates, partials and layouts that
+// are never used, and fields that no page sets in its frontmatter.
+func CheckTemplates(site *website.Website) ([]TemplateIssue, error) {
+	issues := []TemplateIssue{}
+
+	layouts := map[string]*templateFile{}
+	for name, file := range site.LayoutFiles {
+		layout, issue, err := parseTemplateFile(file, name, false)
+		if err != nil {
+			return nil, err
+		}
+		if issue != nil {
+			issues = append(issues, *issue)
+			continue
+		}
+		layouts[name] = layout
+	}
+
+	// partials are parsed once, section partials are shared by many pages
+	parsedPartials := map[string]*templateFile{}
+	parsePartial := func(name string, file string) (*templateFile, error) {
+		if partial, ok := parsedPartials[file]; ok {
+			return partial, nil
+		}
+		partial, issue, err := parseTemplateFile(file, name, false)
+		if err != nil {
+			return nil, err
+		}
+		if issue != nil {
+			issues = append(issues, *issue)
+		}
+		parsedPartials[file] = partial
+		return partial, nil
+	}
+
+	partials := []*templateFile{}
+	for _, name := range sortedKeys(site.Partials) {
+		partial, err := parsePartial(name, site.Partials[name])
+		if err != nil {
+			return nil, err
+		}
+		if partial != nil {
+			partials = append(partials, partial)
+		}
+	}
+
+	// pages sharing an input file, like data pages, only need checking once
+	pages := []*templateFile{}
+	seenPages := map[string]bool{}
+	knownFields := map[string]bool{website.PageKey: true}
+	usedLayouts := map[string]bool{}
+	for _, page := range site.Pages {
+		for key := range page.Data {
+			knownFields[key] = true
+		}
+		if seenPages[page.InputPath] || page.Format != website.HTMLFormat {
+			continue
+		}
+		seenPages[page.InputPath] = true
+		usedLayouts[page.Layout] = true
+
+		pageFile, issue, err := parseTemplateFile(page.InputPath, page.InputPath, true)
+		if err != nil {
+			return nil, err
+		}
+		if issue != nil {
+			issues = append(issues, *issue)
+			continue
+		}
+		pageFile.layout = page.Layout
+		for _, name := range sortedKeys(page.Partials) {
+			partial, err := parsePartial(name, page.Partials[name])
+			if err != nil {
+				return nil, err
+			}
+			if partial != nil {
+				pageFile.partials = append(pageFile.partials, partial)
+			}
+		}
+		pages = append(pages, pageFile)
+	}
+
+	// every template reachable by a page must resolve to a definition
+	referenced := map[string]bool{}
+	reported := map[string]bool{}
+	for _, page := range pages {
+		defined := map[string]bool{}
+		sets := []*templateFile{page}
+		if layout, ok := layouts[page.layout]; ok {
+			sets = append(sets, layout)
+		}
+		sets = append(sets, partials...)
+		sets = append(sets, page.partials...)
+		for _, set := range sets {
+			for name := range set.defines {
+				defined[name] = true
+			}
+		}
+
+		for _, set := range sets {
+			for _, ref := range getTemplateRefs(set.trees) {
+				referenced[ref.name] = true
+				if defined[ref.name] {
+					continue
+				}
+
+				location := ref.location()
+				message := fmt.Sprintf("template %q is not defined", ref.name)
+				if set != page {
+					message = fmt.Sprintf("template %q is not defined for page %s", ref.name, page.path)
+				}
+				if !reported[location+message] {
+					reported[location+message] = true
+					issues = append(issues, TemplateIssue{Location: location, Message: message})
+				}
+			}
+		}
+	}
+
+	for _, page := range pages {
+		for _, shortcode := range page.shortcodes {
+			if _, ok := site.Shortcodes[shortcode.name]; !ok {
+				issues = append(issues, TemplateIssue{
+					Location: shortcode.location(),
+					Message:  fmt.Sprintf("shortcode %q is not defined", shortcode.name)})
+			}
+		}
+	}
+
+	for _, file := range sortedKeys(parsedPartials) {
+		partial := parsedPartials[file]
+		if partial == nil {
+			continue
+		}
+		used := false
+		for name := range partial.defines {
+			used = used || referenced[name]
+		}
+		if !used {
+			issues = append(issues, TemplateIssue{Location: partial.path, Message: "partial is never used"})
+		}
+	}
+
+	for name, layout := range layouts {
+		if !usedLayouts[name] {
+			issues = append(issues, TemplateIssue{Location: layout.path, Message: "layout is not used by any page"})
+		}
+	}
+
+	// fields on the page data must be set by at least one page
+	all := append([]*templateFile{}, pages...)
+	for _, file := range sortedKeys(parsedPartials) {
+		if partial := parsedPartials[file]; partial != nil {
+			all = append(all, partial)
+		}
+	}
+	for _, layout := range layouts {
+		all = append(all, layout)
+	}
+	for _, file := range all {
+		for _, tree := range file.trees {
+			walkDataFields(tree, tree.Root, true, func(field string, node parse.Node) {
+				if !knownFields[field] {
+					issues = append(issues, TemplateIssue{
+						Location: location(tree, node),
+						Message:  fmt.Sprintf("field .%s is not set by any page frontmatter", field)})
+				}
+			})
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Location < issues[j].Location
+	})
+	return issues, nil
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func parseTemplateFile(file string, name string, isPage bool) (*templateFile, *TemplateIssue, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read template %s: %w", file, err)
+	}
+
+	text := string(content)
+	if isPage {
+		pageContent, _, err := render.ReadPage(strings.NewReader(text))
+		if err != nil {
+			return nil, &TemplateIssue{Location: file, Message: err.Error()}, nil
+		}
+
+		// keep the frontmatter lines so positions match the page file
+		frontmatter := text[:len(text)-len(pageContent)]
+		text = strings.Repeat("\n", strings.Count(frontmatter, "\n")) + pageContent
+	}
+
+	parsed := &templateFile{path: file, defines: map[string]bool{}}
+	if isPage {
+		tags, err := render.ParseShortcodes(text)
+		var shortcodeErr *render.ShortcodeError
+		if errors.As(err, &shortcodeErr) {
+			location := fmt.Sprintf("%s:%d:%d", file, shortcodeErr.Line, shortcodeErr.Column)
+			return nil, &TemplateIssue{Location: location, Message: shortcodeErr.Err.Error()}, nil
+		}
+
+		// shortcodes are not template syntax, blank them out keeping the
+		// line breaks so that the positions of the rest are unchanged
+		for _, tag := range tags {
+			if !tag.Closing {
+				location := fmt.Sprintf("%s:%d:%d", file,
+					strings.Count(text[:tag.Start], "\n")+1,
+					tag.Start-strings.LastIndex(text[:tag.Start], "\n"))
+				parsed.shortcodes = append(parsed.shortcodes, templateRef{name: tag.Name, at: location})
+			}
+			blank := strings.Map(func(r rune) rune {
+				if r == '\n' {
+					return r
+				}
+				return ' '
+			}, text[tag.Start:tag.End])
+			text = text[:tag.Start] + blank + text[tag.End:]
+		}
+	}
+
+	// the file is parsed under its path so locations name the file
+	t, err := template.New(file).Funcs(website.TemplateFuncs).Parse(text)
+	if err != nil {
+		return nil, &TemplateIssue{Location: file, Message: err.Error()}, nil
+	}
+
+	for _, associated := range t.Templates() {
+		if associated.Tree == nil {
+			continue
+		}
+		parsed.trees = append(parsed.trees, associated.Tree)
+
+		// the file level template is known by its name, not its path
+		if associated.Name() == file {
+			if !isPage && parse.IsEmptyTree(associated.Tree.Root) {
+				continue
+			}
+			parsed.defines[name] = true
+			continue
+		}
+		parsed.defines[associated.Name()] = true
+	}
+	return parsed, nil, nil
+}
+
+func getTemplateRefs(trees []*parse.Tree) []templateRef {
+	refs := []templateRef{}
+	for _, tree := range trees {
+		walkNodes(tree.Root, func(node parse.Node) {
+			if templateNode, ok := node.(*parse.TemplateNode); ok {
+				refs = append(refs, templateRef{name: templateNode.Name, tree: tree, node: node})
+			}
+		})
+	}
+	return refs
+}
+
+func (r templateRef) location() string {
+	if r.at != "" {
+		return r.at
+	}
+	return location(r.tree, r.node)
+}
+
+func location(tree *parse.Tree, node parse.Node) string {
+	location, _ := tree.ErrorContext(node)
+	return location
+}
+
+func walkNodes(node parse.Node, fn func(parse.Node)) {
+	if node == nil {
+		return
+	}
+	fn(node)
+
+	switch node := node.(type) {
+	case *parse.ListNode:
+		if node == nil {
+			return
+		}
+		for _, child := range node.Nodes {
+			walkNodes(child, fn)
+		}
+	case *parse.IfNode:
+		walkNodes(node.List, fn)
+		walkNodes(node.ElseList, fn)
+	case *parse.RangeNode:
+		walkNodes(node.List, fn)
+		walkNodes(node.ElseList, fn)
+	case *parse.WithNode:
+		walkNodes(node.List, fn)
+		walkNodes(node.ElseList, fn)
+	}
+}
+
+// walkDataFields calls fn for the first field of every reference to the page
+// data. Inside range and with the dot is rebound, so only references through
+// $ are followed there.
+func walkDataFields(tree *parse.Tree, node parse.Node, dotIsData bool, fn func(string, parse.Node)) {
+	switch node := node.(type) {
+	case *parse.ListNode:
+		if node == nil {
+			return
+		}
+		for _, child := range node.Nodes {
+			walkDataFields(tree, child, dotIsData, fn)
+		}
+	case *parse.ActionNode:
+		walkPipeFields(node.Pipe, dotIsData, fn)
+	case *parse.TemplateNode:
+		walkPipeFields(node.Pipe, dotIsData, fn)
+	case *parse.IfNode:
+		walkPipeFields(node.Pipe, dotIsData, fn)
+		walkDataFields(tree, node.List, dotIsData, fn)
+		walkDataFields(tree, node.ElseList, dotIsData, fn)
+	case *parse.RangeNode:
+		walkPipeFields(node.Pipe, dotIsData, fn)
+		walkDataFields(tree, node.List, false, fn)
+		walkDataFields(tree, node.ElseList, dotIsData, fn)
+	case *parse.WithNode:
+		walkPipeFields(node.Pipe, dotIsData, fn)
+		walkDataFields(tree, node.List, false, fn)
+		walkDataFields(tree, node.ElseList, dotIsData, fn)
+	}
+}
+
+func walkPipeFields(pipe *parse.PipeNode, dotIsData bool, fn func(string, parse.Node)) {
+	if pipe == nil {
+		return
+	}
+	for _, cmd := range pipe.Cmds {
+		for _, arg := range cmd.Args {
+			switch arg := arg.(type) {
+			case *parse.FieldNode:
+				if dotIsData {
+					fn(arg.Ident[0], arg)
+				}
+			case *parse.VariableNode:
+				if arg.Ident[0] == "$" && len(arg.Ident) > 1 {
+					fn(arg.Ident[1], arg)
+				}
+			case *parse.PipeNode:
+				walkPipeFields(arg, dotIsData, fn)
+			}
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package check
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eastcitysoftware/ditto/internal/website"
+)
+
+func TestCheckTemplates(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"pages/layouts/default.tmpl": `<h1>{{.title}}</h1>{{.Page.TableOfContents}}{{template "_nav.tmpl" .}}{{block "content" .}}{{end}}`,
+		"pages/layouts/unused.tmpl":  `{{block "content" .}}{{end}}`,
+		"pages/layouts/_nav.tmpl":    `{{range .links}}{{.url}}{{end}}`,
+		"pages/layouts/_footer.tmpl": `{{define "footer"}}{{end}}`,
+		"pages/index.tmpl": `{{/*
+{"title": "Home", "links": []}
+*/}}
+{{define "content"}}{{.titel}}{{template "sidebar" .}}{{end}}
+{{define "extra"}}{{< note >}}{{< gallery >}}{{end}}`,
+		"pages/shortcodes/note.tmpl": `note`,
+	})
+	if err := os.MkdirAll(filepath.Join(root, "public"), os.ModePerm); err != nil {
+		t.Fatalf("failed to create output directory: %v", err)
+	}
+
+	config, err := website.NewConfig(root)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	site, err := website.Load(config)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	issues, err := CheckTemplates(site)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := []string{
+		`_footer.tmpl: partial is never used`,
+		`unused.tmpl: layout is not used by any page`,
+		`index.tmpl:4:22: field .titel is not set by any page frontmatter`,
+		`index.tmpl:4:41: template "sidebar" is not defined`,
+		`index.tmpl:5:31: shortcode "gallery" is not defined`,
+	}
+	if len(issues) != len(expected) {
+		t.Fatalf("expected %d issues, got %d: %v", len(expected), len(issues), issues)
+	}
+	for _, want := range expected {
+		found := false
+		for _, issue := range issues {
+			found = found || strings.HasSuffix(issue.String(), want)
+		}
+		if !found {
+			t.Errorf("expected issue ending %q, got %v", want, issues)
+		}
+	}
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+			t.Fatalf("failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+}
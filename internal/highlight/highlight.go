@@ -0,0 +1,209 @@
+package highlight
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type Options struct {
+	LineNumbers     bool
+	LineNumberStart int
+	HighlightLines  [][2]int
+}
+
+// ParseOptions reads options written as comma or space separated key=value
+// pairs, for example "linenos=true,hl_lines=2-4 7".
+func ParseOptions(value string) (Options, error) {
+	options := Options{LineNumberStart: 1}
+	fields := strings.FieldsFunc(value, func(r rune) bool { return r == ',' })
+	for i := 0; i < len(fields); i++ {
+		key, val, _ := strings.Cut(strings.TrimSpace(fields[i]), "=")
+		switch key {
+		case "":
+			continue
+		case "linenos":
+			options.LineNumbers = val == "" || val == "true" || val == "table" || val == "inline"
+		case "linenostart":
+			start, err := strconv.Atoi(val)
+			if err != nil {
+				return options, fmt.Errorf("linenostart must be a number, got %q", val)
+			}
+			options.LineNumberStart = start
+		case "hl_lines":
+			ranges, err := parseLineRanges(val)
+			if err != nil {
+				return options, err
+			}
+			options.HighlightLines = append(options.HighlightLines, ranges...)
+		default:
+			return options, fmt.Errorf("unknown highlight option %q", key)
+		}
+	}
+	return options, nil
+}
+
+// parseLineRanges reads line numbers and ranges like "2-4 7"
+func parseLineRanges(value string) ([][2]int, error) {
+	ranges := [][2]int{}
+	for _, part := range strings.Fields(value) {
+		from, to, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(from)
+		if err != nil {
+			return nil, fmt.Errorf("invalid line range %q", part)
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(to); err != nil || end < start {
+				return nil, fmt.Errorf("invalid line range %q", part)
+			}
+		}
+		ranges = append(ranges, [2]int{start, end})
+	}
+	return ranges, nil
+}
+
+func (o Options) isHighlighted(line int) bool {
+	for _, r := range o.HighlightLines {
+		if line >= r[0] && line <= r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// Highlight returns code as html with each token wrapped in a span with a
+// class for its kind. Code in an unsupported language is escaped only.
+func Highlight(code string, lang string, options Options) string {
+	lang = strings.ToLower(lang)
+	code = strings.TrimSuffix(code, "\n")
+
+	tokens := []token{{classPlain, code}}
+	if definition, ok := languages[lang]; ok {
+		tokens = tokenize(code, definition)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`<pre class="highlight"><code`)
+	if lang != "" {
+		fmt.Fprintf(&sb, ` class="language-%s" data-lang="%s"`, html.EscapeString(lang), html.EscapeString(lang))
+	}
+	sb.WriteString(">")
+
+	if !options.LineNumbers && len(options.HighlightLines) == 0 {
+		writeTokens(&sb, tokens)
+	} else {
+		for i, line := range splitLines(tokens) {
+			class := "line"
+			if options.isHighlighted(i + 1) {
+				class += " hl"
+			}
+			fmt.Fprintf(&sb, `<span class="%s">`, class)
+			if options.LineNumbers {
+				fmt.Fprintf(&sb, `<span class="ln">%d</span>`, options.LineNumberStart+i)
+			}
+			sb.WriteString(`<span class="cl">`)
+			writeTokens(&sb, line)
+			sb.WriteString("\n</span></span>")
+		}
+	}
+
+	sb.WriteString("</code></pre>")
+	return sb.String()
+}
+
+func writeTokens(sb *strings.Builder, tokens []token) {
+	for _, token := range tokens {
+		if token.class == classPlain {
+			sb.WriteString(html.EscapeString(token.text))
+			continue
+		}
+		fmt.Fprintf(sb, `<span class="%s">%s</span>`, token.class, html.EscapeString(token.text))
+	}
+}
+
+// splitLines splits tokens at line breaks, tokens spanning lines like block
+// comments are split into a token per line
+func splitLines(tokens []token) [][]token {
+	lines := [][]token{{}}
+	for _, t := range tokens {
+		parts := strings.Split(t.text, "\n")
+		for i, part := range parts {
+			if i > 0 {
+				lines = append(lines, []token{})
+			}
+			if part != "" {
+				lines[len(lines)-1] = append(lines[len(lines)-1], token{t.class, part})
+			}
+		}
+	}
+	return lines
+}
+
+var codeBlockPattern = regexp.MustCompile(`(?s)<pre([^>]*)>\s*<code([^>]*)>(.*?)</code>\s*</pre>`)
+var attrPattern = regexp.MustCompile(`([a-zA-Z_:][-a-zA-Z0-9_:.]*)(?:\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+)))?`)
+
+// fencePattern matches a fenced code block written in a page, the fences are
+// at the start of a line and options follow the language in braces:
+// ```go {linenos=true,hl_lines="2-4"}
+var fencePattern = regexp.MustCompile(`(?m)^[ \t]*` + "```" + `([\w+#.-]*)[ \t]*(?:\{([^}\n]*)\})?[ \t]*\n((?s:.*?))^[ \t]*` + "```" + `[ \t]*$`)
+
+// HighlightBlocks highlights the code blocks in rendered html. Fenced code
+// written in pages is highlighted with the options after its language, and
+// blocks as written by markdown renderers, <pre><code class="language-go">,
+// read line numbers and highlighted lines from data-linenos and
+// data-hl-lines attributes on the code or pre element. Html blocks without a
+// supported language are left as they are.
+func HighlightBlocks(content string) string {
+	content = fencePattern.ReplaceAllStringFunc(content, func(block string) string {
+		match := fencePattern.FindStringSubmatch(block)
+		options, err := ParseOptions(strings.ReplaceAll(match[2], `"`, ""))
+		if err != nil {
+			return block
+		}
+		return Highlight(match[3], match[1], options)
+	})
+
+	return codeBlockPattern.ReplaceAllStringFunc(content, func(block string) string {
+		match := codeBlockPattern.FindStringSubmatch(block)
+		attrs := parseAttrs(match[1])
+		for key, value := range parseAttrs(match[2]) {
+			attrs[key] = value
+		}
+
+		lang := ""
+		for _, class := range strings.Fields(attrs["class"]) {
+			if after, ok := strings.CutPrefix(class, "language-"); ok {
+				lang = after
+			}
+		}
+		// blocks containing markup have already been highlighted
+		if !IsSupported(lang) || strings.Contains(match[3], "<") {
+			return block
+		}
+
+		options := Options{LineNumberStart: 1}
+		if _, ok := attrs["data-linenos"]; ok {
+			options.LineNumbers = attrs["data-linenos"] != "false"
+		}
+		if lines, ok := attrs["data-hl-lines"]; ok {
+			ranges, err := parseLineRanges(strings.ReplaceAll(lines, ",", " "))
+			if err != nil {
+				return block
+			}
+			options.HighlightLines = ranges
+		}
+
+		return Highlight(html.UnescapeString(match[3]), lang, options)
+	})
+}
+
+func parseAttrs(tag string) map[string]string {
+	attrs := map[string]string{}
+	for _, match := range attrPattern.FindAllStringSubmatch(tag, -1) {
+		attrs[strings.ToLower(match[1])] = html.UnescapeString(match[2] + match[3] + match[4])
+	}
+	return attrs
+}
@@ -0,0 +1,112 @@
+package highlight
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHighlightGo(t *testing.T) {
+	got := Highlight("func main() {\n\treturn \"hi\" // done\n}\n", "go", Options{})
+
+	for _, want := range []string{
+		`<pre class="highlight"><code class="language-go" data-lang="go">`,
+		`<span class="k">func</span> <span class="nf">main</span>`,
+		`<span class="k">return</span> <span class="s">&#34;hi&#34;</span>`,
+		`<span class="c">// done</span>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in %s", want, got)
+		}
+	}
+}
+
+func TestHighlightUnsupportedLanguageIsEscaped(t *testing.T) {
+	got := Highlight("a < b", "cobol", Options{})
+	want := `<pre class="highlight"><code class="language-cobol" data-lang="cobol">a &lt; b</code></pre>`
+	if got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestHighlightLineNumbersAndRanges(t *testing.T) {
+	options, err := ParseOptions("linenos=true,linenostart=10,hl_lines=2-3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := Highlight("a\nb\nc\n", "", options)
+
+	want := `<pre class="highlight"><code>` +
+		`<span class="line"><span class="ln">10</span><span class="cl">a` + "\n" + `</span></span>` +
+		`<span class="line hl"><span class="ln">11</span><span class="cl">b` + "\n" + `</span></span>` +
+		`<span class="line hl"><span class="ln">12</span><span class="cl">c` + "\n" + `</span></span>` +
+		`</code></pre>`
+	if got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestParseOptionsErrors(t *testing.T) {
+	for _, value := range []string{"hl_lines=3-1", "linenostart=x", "colour=red"} {
+		if _, err := ParseOptions(value); err == nil {
+			t.Errorf("expected an error for %q", value)
+		}
+	}
+}
+
+func TestHighlightBlocks(t *testing.T) {
+	content := `<p>x</p><pre><code class="language-python" data-hl-lines="1">def f():
+    return 1 &lt; 2</code></pre><pre><code>plain</code></pre>`
+	got := HighlightBlocks(content)
+
+	for _, want := range []string{
+		`<p>x</p><pre class="highlight"><code class="language-python" data-lang="python">`,
+		`<span class="line hl"><span class="cl"><span class="k">def</span> <span class="nf">f</span>`,
+		`<span class="o">&lt;</span>`,
+		`<pre><code>plain</code></pre>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in %s", want, got)
+		}
+	}
+
+	// highlighting twice leaves the highlighted block alone
+	if again := HighlightBlocks(got); again != got {
+		t.Errorf("expected highlighted blocks to be unchanged, got %s", again)
+	}
+}
+
+func TestHighlightFencedBlocks(t *testing.T) {
+	content := "<p>x</p>\n```go {linenos=true,hl_lines=\"2\"}\nfunc f() {\n\treturn a < b\n}\n```\n" +
+		"```\n<b>plain</b>\n```\n<p>y</p>"
+	got := HighlightBlocks(content)
+
+	for _, want := range []string{
+		`<p>x</p>` + "\n" + `<pre class="highlight"><code class="language-go" data-lang="go">`,
+		`<span class="line hl"><span class="ln">2</span>`,
+		`<span class="o">&lt;</span>`,
+		`<pre class="highlight"><code>&lt;b&gt;plain&lt;/b&gt;</code></pre>`,
+		"</pre>\n<p>y</p>",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in %s", want, got)
+		}
+	}
+	if strings.Contains(got, "```") {
+		t.Errorf("expected the fences to be replaced, got %s", got)
+	}
+}
+
+func TestCSS(t *testing.T) {
+	for _, theme := range Themes() {
+		css, err := CSS(theme)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(css, ".highlight .k") {
+			t.Errorf("expected keyword rule in %s theme", theme)
+		}
+	}
+	if _, err := CSS("missing"); err == nil {
+		t.Error("expected an error for an unknown theme")
+	}
+}
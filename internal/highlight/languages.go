@@ -0,0 +1,138 @@
+package highlight
+
+import "strings"
+
+// language describes how source code is split into tokens. Languages are
+// matched by simple rules rather than full grammars, which is enough to
+// colour keywords, strings, comments and numbers.
+type language struct {
+	lineComments    []string
+	blockComments   [][2]string
+	quotes          []string
+	rawQuotes       []string
+	keywords        map[string]bool
+	types           map[string]bool
+	builtins        map[string]bool
+	caseInsensitive bool
+	identChars      string
+	variablePrefix  string
+	markup          bool
+	keyValue        bool
+}
+
+func words(s string) map[string]bool {
+	set := map[string]bool{}
+	for _, word := range strings.Fields(s) {
+		set[word] = true
+	}
+	return set
+}
+
+var golang = &language{
+	lineComments:  []string{"//"},
+	blockComments: [][2]string{{"/*", "*/"}},
+	quotes:        []string{`"`, `'`},
+	rawQuotes:     []string{"`"},
+	keywords: words(`break case chan const continue default defer else fallthrough for func go goto if
+		import interface map package range return select struct switch type var`),
+	types: words(`bool byte complex64 complex128 error float32 float64 int int8 int16 int32 int64 rune
+		string uint uint8 uint16 uint32 uint64 uintptr any comparable`),
+	builtins: words(`append cap clear close complex copy delete imag len make max min new panic print
+		println real recover true false nil iota`),
+}
+
+var javascript = &language{
+	lineComments:  []string{"//"},
+	blockComments: [][2]string{{"/*", "*/"}},
+	quotes:        []string{`"`, `'`, "`"},
+	keywords: words(`async await break case catch class const continue debugger default delete do else
+		export extends finally for from function if import in instanceof let new of return static super
+		switch this throw try typeof var void while with yield as implements interface type enum`),
+	types:      words(`string number boolean object symbol bigint unknown never any void`),
+	builtins:   words(`true false null undefined NaN Infinity console window document Promise Array Object JSON Math`),
+	identChars: "$",
+}
+
+var python = &language{
+	lineComments: []string{"#"},
+	quotes:       []string{`"""`, `'''`, `"`, `'`},
+	keywords: words(`and as assert async await break class continue def del elif else except finally
+		for from global if import in is lambda nonlocal not or pass raise return try while with yield match case`),
+	types:    words(`int float str bool bytes list dict set tuple object`),
+	builtins: words(`True False None print len range open enumerate zip map filter isinstance super self`),
+}
+
+var bash = &language{
+	lineComments:   []string{"#"},
+	quotes:         []string{`"`, `'`},
+	keywords:       words(`if then else elif fi case esac for while until do done in function return export local`),
+	builtins:       words(`echo cd ls cat grep sed awk set unset source exit read printf test mkdir rm cp mv go git`),
+	variablePrefix: "$",
+	identChars:     "-",
+}
+
+var jsonLanguage = &language{
+	quotes:   []string{`"`},
+	builtins: words(`true false null`),
+	keyValue: true,
+}
+
+var yaml = &language{
+	lineComments: []string{"#"},
+	quotes:       []string{`"`, `'`},
+	builtins:     words(`true false null yes no on off`),
+	identChars:   "-",
+	keyValue:     true,
+}
+
+var css = &language{
+	blockComments: [][2]string{{"/*", "*/"}},
+	quotes:        []string{`"`, `'`},
+	keywords:      words(`@media @import @font-face @keyframes @supports @layer !important`),
+	builtins:      words(`inherit initial unset none auto`),
+	identChars:    "-@!",
+	keyValue:      true,
+}
+
+var sql = &language{
+	lineComments:  []string{"--"},
+	blockComments: [][2]string{{"/*", "*/"}},
+	quotes:        []string{`'`, `"`},
+	keywords: words(`select from where insert into values update set delete create table drop alter
+		index join left right inner outer on group by order having limit offset as and or not null is
+		in like between distinct union all primary key foreign references default case when then else end`),
+	types:           words(`int integer bigint smallint text varchar char boolean date timestamp numeric real serial`),
+	builtins:        words(`count sum avg min max coalesce now true false`),
+	caseInsensitive: true,
+}
+
+var markup = &language{markup: true}
+
+var languages = map[string]*language{
+	"go":         golang,
+	"golang":     golang,
+	"js":         javascript,
+	"javascript": javascript,
+	"ts":         javascript,
+	"typescript": javascript,
+	"py":         python,
+	"python":     python,
+	"sh":         bash,
+	"bash":       bash,
+	"shell":      bash,
+	"json":       jsonLanguage,
+	"yaml":       yaml,
+	"yml":        yaml,
+	"css":        css,
+	"sql":        sql,
+	"html":       markup,
+	"xml":        markup,
+	"svg":        markup,
+	"tmpl":       markup,
+}
+
+// IsSupported reports whether code in lang is highlighted.
+func IsSupported(lang string) bool {
+	_, ok := languages[strings.ToLower(lang)]
+	return ok
+}
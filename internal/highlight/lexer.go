@@ -0,0 +1,301 @@
+package highlight
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// token classes, named after the short classes used by pygments and chroma
+// so existing stylesheets can be reused
+const (
+	classPlain       = ""
+	classKeyword     = "k"
+	classType        = "kt"
+	classBuiltin     = "nb"
+	classFunction    = "nf"
+	classVariable    = "nv"
+	classString      = "s"
+	classComment     = "c"
+	classNumber      = "m"
+	classOperator    = "o"
+	classPunctuation = "p"
+	classTag         = "nt"
+	classAttribute   = "na"
+)
+
+const (
+	operatorChars    = "+-*/%=&|<>!^~?:"
+	punctuationChars = "{}[]();,."
+)
+
+type token struct {
+	class string
+	text  string
+}
+
+func tokenize(code string, lang *language) []token {
+	if lang.markup {
+		return tokenizeMarkup(code)
+	}
+
+	tokens := []token{}
+	emit := func(class string, text string) {
+		// merge adjacent tokens of the same class to keep the output small
+		if n := len(tokens); n > 0 && tokens[n-1].class == class {
+			tokens[n-1].text += text
+			return
+		}
+		tokens = append(tokens, token{class: class, text: text})
+	}
+
+	i := 0
+	for i < len(code) {
+		rest := code[i:]
+
+		if end := matchComment(rest, lang); end > 0 {
+			emit(classComment, rest[:end])
+			i += end
+			continue
+		}
+
+		if end := matchString(rest, lang); end > 0 {
+			class := classString
+			if lang.keyValue && isKey(code[i+end:]) {
+				class = classAttribute
+			}
+			emit(class, rest[:end])
+			i += end
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(rest)
+		switch {
+		case unicode.IsSpace(r):
+			emit(classPlain, rest[:size])
+			i += size
+
+		case isDigit(r) || (r == '.' && len(rest) > 1 && isDigit(rune(rest[1]))) || (r == '#' && lang == css):
+			end := 1
+			for end < len(rest) && (isIdentRune(rune(rest[end]), "") || rest[end] == '.') {
+				end++
+			}
+			emit(classNumber, rest[:end])
+			i += end
+
+		case lang.variablePrefix != "" && strings.HasPrefix(rest, lang.variablePrefix) && len(rest) > 1 &&
+			(isIdentRune(rune(rest[1]), "") || rest[1] == '{'):
+			end := 1
+			for end < len(rest) && (isIdentRune(rune(rest[end]), "") || strings.ContainsRune("{}", rune(rest[end]))) {
+				end++
+			}
+			emit(classVariable, rest[:end])
+			i += end
+
+		case isIdentStart(r, lang.identChars):
+			end := size
+			for end < len(rest) {
+				next, nextSize := utf8.DecodeRuneInString(rest[end:])
+				if !isIdentRune(next, lang.identChars) {
+					break
+				}
+				end += nextSize
+			}
+			word := rest[:end]
+			emit(classifyWord(word, code[i+end:], lang), word)
+			i += end
+
+		case strings.ContainsRune(operatorChars, r):
+			emit(classOperator, rest[:size])
+			i += size
+
+		case strings.ContainsRune(punctuationChars, r):
+			emit(classPunctuation, rest[:size])
+			i += size
+
+		default:
+			emit(classPlain, rest[:size])
+			i += size
+		}
+	}
+	return tokens
+}
+
+func classifyWord(word string, after string, lang *language) string {
+	key := word
+	if lang.caseInsensitive {
+		key = strings.ToLower(word)
+	}
+
+	switch {
+	case lang.keywords[key]:
+		return classKeyword
+	case lang.types[key]:
+		return classType
+	case lang.builtins[key]:
+		return classBuiltin
+	case lang.keyValue && isKey(after):
+		return classAttribute
+	case strings.HasPrefix(strings.TrimLeft(after, " \t"), "("):
+		return classFunction
+	}
+	return classPlain
+}
+
+// isKey reports whether the text following a word or string marks it as the
+// key of a key value pair, like "key": in json or key: in yaml
+func isKey(after string) bool {
+	after = strings.TrimLeft(after, " \t")
+	return strings.HasPrefix(after, ":") && !strings.HasPrefix(after, "::")
+}
+
+func matchComment(rest string, lang *language) int {
+	for _, prefix := range lang.lineComments {
+		if strings.HasPrefix(rest, prefix) {
+			if end := strings.IndexByte(rest, '\n'); end != -1 {
+				return end
+			}
+			return len(rest)
+		}
+	}
+	for _, delims := range lang.blockComments {
+		if strings.HasPrefix(rest, delims[0]) {
+			if end := strings.Index(rest[len(delims[0]):], delims[1]); end != -1 {
+				return len(delims[0]) + end + len(delims[1])
+			}
+			return len(rest)
+		}
+	}
+	return 0
+}
+
+func matchString(rest string, lang *language) int {
+	for _, quote := range lang.rawQuotes {
+		if strings.HasPrefix(rest, quote) {
+			if end := strings.Index(rest[len(quote):], quote); end != -1 {
+				return len(quote) + end + len(quote)
+			}
+			return len(rest)
+		}
+	}
+	for _, quote := range lang.quotes {
+		if !strings.HasPrefix(rest, quote) {
+			continue
+		}
+
+		// escaped characters never close the string
+		i := len(quote)
+		for i < len(rest) {
+			if rest[i] == '\\' {
+				i += 2
+				continue
+			}
+			if strings.HasPrefix(rest[i:], quote) {
+				return i + len(quote)
+			}
+			// single line strings end at the end of the line
+			if rest[i] == '\n' && len(quote) == 1 && quote != "`" {
+				return i
+			}
+			i++
+		}
+		return len(rest)
+	}
+	return 0
+}
+
+func tokenizeMarkup(code string) []token {
+	tokens := []token{}
+	i := 0
+	for i < len(code) {
+		rest := code[i:]
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			end := strings.Index(rest, "-->")
+			if end == -1 {
+				end = len(rest)
+			} else {
+				end += 3
+			}
+			tokens = append(tokens, token{classComment, rest[:end]})
+			i += end
+
+		case strings.HasPrefix(rest, "<") && len(rest) > 1 && (isIdentStart(rune(rest[1]), "/!?")):
+			end := strings.IndexByte(rest, '>')
+			if end == -1 {
+				end = len(rest) - 1
+			}
+			tokens = append(tokens, tokenizeTag(rest[:end+1])...)
+			i += end + 1
+
+		default:
+			end := strings.IndexByte(rest[1:], '<')
+			if end == -1 {
+				end = len(rest)
+			} else {
+				end++
+			}
+			tokens = append(tokens, token{classPlain, rest[:end]})
+			i += end
+		}
+	}
+	return tokens
+}
+
+func tokenizeTag(tag string) []token {
+	// the tag name including its opening bracket
+	end := 1
+	for end < len(tag) && !unicode.IsSpace(rune(tag[end])) && tag[end] != '>' && !(tag[end] == '/' && end > 1) {
+		end++
+	}
+	tokens := []token{{classTag, tag[:end]}}
+
+	i := end
+	for i < len(tag) {
+		rest := tag[i:]
+		c := rest[0]
+		switch {
+		case c == '"' || c == '\'':
+			close := strings.IndexByte(rest[1:], c)
+			if close == -1 {
+				close = len(rest) - 1
+			} else {
+				close += 2
+			}
+			tokens = append(tokens, token{classString, rest[:close]})
+			i += close
+		case c == '>' || c == '/' || c == '?':
+			tokens = append(tokens, token{classTag, string(c)})
+			i++
+		case c == '=':
+			tokens = append(tokens, token{classOperator, "="})
+			i++
+		case unicode.IsSpace(rune(c)):
+			tokens = append(tokens, token{classPlain, string(c)})
+			i++
+		default:
+			end := 0
+			for end < len(rest) && !unicode.IsSpace(rune(rest[end])) && !strings.ContainsRune("=>/\"'", rune(rest[end])) {
+				end++
+			}
+			if end == 0 {
+				end = 1
+			}
+			tokens = append(tokens, token{classAttribute, rest[:end]})
+			i += end
+		}
+	}
+	return tokens
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func isIdentStart(r rune, extra string) bool {
+	return unicode.IsLetter(r) || r == '_' || strings.ContainsRune(extra, r)
+}
+
+func isIdentRune(r rune, extra string) bool {
+	return isIdentStart(r, extra) || unicode.IsDigit(r)
+}
@@ -0,0 +1,93 @@
+package highlight
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type theme struct {
+	background string
+	foreground string
+	highlight  string
+	lineNumber string
+	classes    map[string]string
+}
+
+var themes = map[string]theme{
+	"github": {
+		background: "#f6f8fa",
+		foreground: "#24292f",
+		highlight:  "#fff8c5",
+		lineNumber: "#8c959f",
+		classes: map[string]string{
+			classKeyword:     "color:#cf222e",
+			classType:        "color:#cf222e",
+			classBuiltin:     "color:#0550ae",
+			classFunction:    "color:#8250df",
+			classVariable:    "color:#953800",
+			classString:      "color:#0a3069",
+			classComment:     "color:#6e7781;font-style:italic",
+			classNumber:      "color:#0550ae",
+			classOperator:    "color:#cf222e",
+			classPunctuation: "color:#24292f",
+			classTag:         "color:#116329",
+			classAttribute:   "color:#0550ae",
+		},
+	},
+	"monokai": {
+		background: "#272822",
+		foreground: "#f8f8f2",
+		highlight:  "#3c3d38",
+		lineNumber: "#75715e",
+		classes: map[string]string{
+			classKeyword:     "color:#66d9ef",
+			classType:        "color:#66d9ef",
+			classBuiltin:     "color:#ae81ff",
+			classFunction:    "color:#a6e22e",
+			classVariable:    "color:#fd971f",
+			classString:      "color:#e6db74",
+			classComment:     "color:#75715e;font-style:italic",
+			classNumber:      "color:#ae81ff",
+			classOperator:    "color:#f92672",
+			classPunctuation: "color:#f8f8f2",
+			classTag:         "color:#f92672",
+			classAttribute:   "color:#a6e22e",
+		},
+	},
+}
+
+// Themes returns the names of the built in stylesheets.
+func Themes() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CSS returns the stylesheet for the classes written by Highlight.
+func CSS(name string) (string, error) {
+	theme, ok := themes[name]
+	if !ok {
+		return "", fmt.Errorf("unknown highlight theme %q, use one of %s", name, strings.Join(Themes(), ", "))
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "/* ditto highlight theme: %s */\n", name)
+	fmt.Fprintf(&sb, ".highlight { background:%s; color:%s; padding:1em; overflow-x:auto; }\n", theme.background, theme.foreground)
+	sb.WriteString(".highlight .line { display:flex; }\n")
+	fmt.Fprintf(&sb, ".highlight .line.hl { background:%s; }\n", theme.highlight)
+	fmt.Fprintf(&sb, ".highlight .ln { color:%s; margin-right:1em; min-width:2em; text-align:right; user-select:none; }\n", theme.lineNumber)
+
+	classes := make([]string, 0, len(theme.classes))
+	for class := range theme.classes {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	for _, class := range classes {
+		fmt.Fprintf(&sb, ".highlight .%s { %s; }\n", class, theme.classes[class])
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,229 @@
+package images
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	Resize = "resize"
+	Fit    = "fit"
+	Fill   = "fill"
+	Crop   = "crop"
+
+	DefaultQuality = 75
+)
+
+var formats = map[string]string{
+	"png":  "png",
+	"jpg":  "jpeg",
+	"jpeg": "jpeg",
+	"gif":  "gif",
+}
+
+// Options are the target size and format of a processed image. A width or
+// height of 0 keeps the aspect ratio of the source, an empty format keeps
+// the source format.
+type Options struct {
+	Width   int
+	Height  int
+	Format  string
+	Quality int
+}
+
+// ParseOptions reads a space separated spec like "800x600 png q80", where
+// the size is "800x", "x600" or "800x600".
+func ParseOptions(spec string) (Options, error) {
+	options := Options{Quality: DefaultQuality}
+	for _, field := range strings.Fields(strings.ToLower(spec)) {
+		if format, ok := formats[field]; ok {
+			options.Format = format
+			continue
+		}
+
+		if quality, ok := strings.CutPrefix(field, "q"); ok {
+			q, err := strconv.Atoi(quality)
+			if err != nil || q < 1 || q > 100 {
+				return options, fmt.Errorf("invalid quality %q, use q1 to q100", field)
+			}
+			options.Quality = q
+			continue
+		}
+
+		width, height, ok := strings.Cut(field, "x")
+		if !ok {
+			return options, fmt.Errorf("unknown image option %q", field)
+		}
+		var err error
+		if width != "" {
+			if options.Width, err = strconv.Atoi(width); err != nil || options.Width < 0 {
+				return options, fmt.Errorf("invalid image size %q", field)
+			}
+		}
+		if height != "" {
+			if options.Height, err = strconv.Atoi(height); err != nil || options.Height < 0 {
+				return options, fmt.Errorf("invalid image size %q", field)
+			}
+		}
+	}
+
+	if options.Width == 0 && options.Height == 0 {
+		return options, fmt.Errorf("image spec %q has no size", spec)
+	}
+	return options, nil
+}
+
+// String is the canonical form of the options, used to name derived images
+func (o Options) String() string {
+	s := fmt.Sprintf("%dx%d", o.Width, o.Height)
+	if o.Format == "jpeg" {
+		s += fmt.Sprintf("_q%d", o.Quality)
+	}
+	return s
+}
+
+// Decode reads an image and the name of its format.
+func Decode(r io.Reader) (image.Image, string, error) {
+	img, format, err := image.Decode(r)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode image: %w", err)
+	}
+	return img, format, nil
+}
+
+// Encode writes an image in the format, one of png, jpeg or gif.
+func Encode(w io.Writer, img image.Image, format string, quality int) error {
+	switch format {
+	case "png":
+		return png.Encode(w, img)
+	case "jpeg":
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+	case "gif":
+		return gif.Encode(w, img, nil)
+	}
+	return fmt.Errorf("unsupported image format %q", format)
+}
+
+// FormatOf returns the format of an image file name from its extension.
+func FormatOf(name string) (string, bool) {
+	ext := strings.ToLower(strings.TrimPrefix(path.Ext(name), "."))
+	format, ok := formats[ext]
+	return format, ok
+}
+
+// Extension returns the file extension for a format.
+func Extension(format string) string {
+	if format == "jpeg" {
+		return ".jpg"
+	}
+	return "." + format
+}
+
+// Process applies one of the methods to an image. Resize scales to the size,
+// Fit scales down to fit within it, Fill scales and crops to cover it and
+// Crop cuts the size from the centre without scaling.
+func Process(img image.Image, method string, options Options) (image.Image, error) {
+	bounds := img.Bounds()
+	width, height := options.Width, options.Height
+
+	switch method {
+	case Resize:
+		width, height = keepAspect(bounds.Dx(), bounds.Dy(), width, height)
+		return scale(img, width, height), nil
+
+	case Fit:
+		if width == 0 || height == 0 {
+			return nil, fmt.Errorf("%s needs a width and height", method)
+		}
+		ratio := min(float64(width)/float64(bounds.Dx()), float64(height)/float64(bounds.Dy()), 1)
+		return scale(img, round(float64(bounds.Dx())*ratio), round(float64(bounds.Dy())*ratio)), nil
+
+	case Fill:
+		if width == 0 || height == 0 {
+			return nil, fmt.Errorf("%s needs a width and height", method)
+		}
+		ratio := max(float64(width)/float64(bounds.Dx()), float64(height)/float64(bounds.Dy()))
+		scaled := scale(img, max(round(float64(bounds.Dx())*ratio), width), max(round(float64(bounds.Dy())*ratio), height))
+		return crop(scaled, width, height), nil
+
+	case Crop:
+		width, height = keepAspect(bounds.Dx(), bounds.Dy(), width, height)
+		return crop(img, min(width, bounds.Dx()), min(height, bounds.Dy())), nil
+	}
+	return nil, fmt.Errorf("unknown image method %q", method)
+}
+
+func keepAspect(srcWidth, srcHeight, width, height int) (int, int) {
+	if width == 0 {
+		width = max(round(float64(srcWidth)*float64(height)/float64(srcHeight)), 1)
+	}
+	if height == 0 {
+		height = max(round(float64(srcHeight)*float64(width)/float64(srcWidth)), 1)
+	}
+	return width, height
+}
+
+func round(f float64) int {
+	return int(f + 0.5)
+}
+
+// scale resamples an image by averaging the source pixels covered by each
+// target pixel, which keeps detail when scaling down
+func scale(img image.Image, width, height int) image.Image {
+	src := toRGBA(img)
+	bounds := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	xRatio := float64(bounds.Dx()) / float64(width)
+	yRatio := float64(bounds.Dy()) / float64(height)
+
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + int(float64(y)*yRatio)
+		y1 := max(bounds.Min.Y+int(float64(y+1)*yRatio), y0+1)
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + int(float64(x)*xRatio)
+			x1 := max(bounds.Min.X+int(float64(x+1)*xRatio), x0+1)
+
+			var r, g, b, a, n uint32
+			for sy := y0; sy < y1 && sy < bounds.Max.Y; sy++ {
+				for sx := x0; sx < x1 && sx < bounds.Max.X; sx++ {
+					c := src.RGBAAt(sx, sy)
+					r, g, b, a = r+uint32(c.R), g+uint32(c.G), b+uint32(c.B), a+uint32(c.A)
+					n++
+				}
+			}
+			if n > 0 {
+				dst.SetRGBA(x, y, color.RGBA{uint8(r / n), uint8(g / n), uint8(b / n), uint8(a / n)})
+			}
+		}
+	}
+	return dst
+}
+
+// crop cuts an area of the size from the centre of the image
+func crop(img image.Image, width, height int) image.Image {
+	bounds := img.Bounds()
+	x := bounds.Min.X + (bounds.Dx()-width)/2
+	y := bounds.Min.Y + (bounds.Dy()-height)/2
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), img, image.Point{x, y}, draw.Src)
+	return dst
+}
+
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok {
+		return rgba
+	}
+	rgba := image.NewRGBA(img.Bounds())
+	draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
+	return rgba
+}
@@ -0,0 +1,113 @@
+package images
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func testImage(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			// left half red, right half blue
+			if x < width/2 {
+				img.SetRGBA(x, y, color.RGBA{255, 0, 0, 255})
+			} else {
+				img.SetRGBA(x, y, color.RGBA{0, 0, 255, 255})
+			}
+		}
+	}
+	return img
+}
+
+func TestParseOptions(t *testing.T) {
+	options, err := ParseOptions("800x png")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if options != (Options{Width: 800, Format: "png", Quality: DefaultQuality}) {
+		t.Errorf("unexpected options %+v", options)
+	}
+
+	options, err = ParseOptions("x300 JPG q90")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if options.String() != "0x300_q90" {
+		t.Errorf("expected canonical options 0x300_q90, got %s", options)
+	}
+
+	for _, spec := range []string{"", "png", "axb", "100x q0", "100x webp"} {
+		if _, err := ParseOptions(spec); err == nil {
+			t.Errorf("expected an error for %q", spec)
+		}
+	}
+}
+
+func TestProcessSizes(t *testing.T) {
+	img := testImage(400, 200)
+	for _, test := range []struct {
+		method        string
+		width, height int
+		expected      image.Point
+	}{
+		{Resize, 100, 0, image.Pt(100, 50)},
+		{Resize, 0, 100, image.Pt(200, 100)},
+		{Resize, 100, 100, image.Pt(100, 100)},
+		{Fit, 100, 100, image.Pt(100, 50)},
+		{Fit, 800, 800, image.Pt(400, 200)},
+		{Fill, 100, 100, image.Pt(100, 100)},
+		{Crop, 50, 500, image.Pt(50, 200)},
+	} {
+		processed, err := Process(img, test.method, Options{Width: test.width, Height: test.height})
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", test.method, err)
+		}
+		if size := processed.Bounds().Size(); size != test.expected {
+			t.Errorf("%s %dx%d: expected %v, got %v", test.method, test.width, test.height, test.expected, size)
+		}
+	}
+
+	if _, err := Process(img, Fill, Options{Width: 100}); err == nil {
+		t.Error("expected fill without a height to fail")
+	}
+}
+
+func TestProcessKeepsContent(t *testing.T) {
+	// filling a square from the centre keeps both colours, cropping the
+	// left keeps only red
+	filled, _ := Process(testImage(400, 200), Fill, Options{Width: 10, Height: 10})
+	if c := color.RGBAModel.Convert(filled.At(0, 5)).(color.RGBA); c.R != 255 || c.B != 0 {
+		t.Errorf("expected red on the left, got %v", c)
+	}
+	if c := color.RGBAModel.Convert(filled.At(9, 5)).(color.RGBA); c.B != 255 || c.R != 0 {
+		t.Errorf("expected blue on the right, got %v", c)
+	}
+
+	// an odd width averages the middle pixel across both halves
+	resized, _ := Process(testImage(4, 1), Resize, Options{Width: 1, Height: 1})
+	if c := color.RGBAModel.Convert(resized.At(0, 0)).(color.RGBA); c.R != 127 || c.B != 127 {
+		t.Errorf("expected an average of red and blue, got %v", c)
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	for _, format := range []string{"png", "jpeg", "gif"} {
+		var buf bytes.Buffer
+		if err := Encode(&buf, testImage(8, 4), format, DefaultQuality); err != nil {
+			t.Fatalf("%s: expected no error, got %v", format, err)
+		}
+		img, decoded, err := Decode(&buf)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", format, err)
+		}
+		if decoded != format || img.Bounds().Dx() != 8 {
+			t.Errorf("expected 8px wide %s, got %d px %s", format, img.Bounds().Dx(), decoded)
+		}
+	}
+	if format, ok := FormatOf("photos/a.JPG"); !ok || format != "jpeg" || Extension(format) != ".jpg" {
+		t.Errorf("expected jpg files to be jpeg, got %s", format)
+	}
+}
@@ -0,0 +1,199 @@
+package memfs
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FS is an in memory file system that rendered output is written to when it
+// is served without touching the disk. Files are named by slash separated
+// paths and directories exist as long as they hold a file. It is safe to
+// write while files are being served.
+type FS struct {
+	mu    sync.RWMutex
+	files map[string]*file
+}
+
+type file struct {
+	data    []byte
+	modTime time.Time
+}
+
+func New() *FS {
+	return &FS{files: map[string]*file{}}
+}
+
+// clean turns a name into a key, without leading or trailing slashes
+func clean(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
+
+// WriteFile creates or replaces a file.
+func (m *FS) WriteFile(name string, data []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[clean(name)] = &file{data: bytes.Clone(data), modTime: time.Now()}
+}
+
+// ReadFile returns the content of a file.
+func (m *FS) ReadFile(name string) ([]byte, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	f, ok := m.files[clean(name)]
+	if !ok {
+		return nil, false
+	}
+	return f.data, true
+}
+
+// Exists reports whether a file exists.
+func (m *FS) Exists(name string) bool {
+	_, ok := m.ReadFile(name)
+	return ok
+}
+
+// Remove removes a file, removing a file that does not exist does nothing.
+func (m *FS) Remove(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.files, clean(name))
+}
+
+// Clear removes every file.
+func (m *FS) Clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files = map[string]*file{}
+}
+
+// Replace swaps in every file of other at once, so readers see either all of
+// the old files or all of the new ones. other must not be used afterwards.
+func (m *FS) Replace(other *FS) {
+	other.mu.Lock()
+	files := other.files
+	other.files = map[string]*file{}
+	other.mu.Unlock()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files = files
+}
+
+// Names returns the names of all files, sorted.
+func (m *FS) Names() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	names := make([]string, 0, len(m.files))
+	for name := range m.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Open opens a file or directory for http.FileServer and the development
+// server, it implements http.FileSystem.
+func (m *FS) Open(name string) (http.File, error) {
+	key := clean(name)
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if f, ok := m.files[key]; ok {
+		return &openFile{
+			Reader: bytes.NewReader(f.data),
+			info:   fileInfo{name: path.Base(key), size: int64(len(f.data)), modTime: f.modTime}}, nil
+	}
+
+	// a directory is open when any file is below it
+	prefix := key + "/"
+	if key == "" {
+		prefix = ""
+	}
+	children := map[string]fileInfo{}
+	for name, f := range m.files {
+		rest, ok := strings.CutPrefix(name, prefix)
+		if !ok {
+			continue
+		}
+		child, _, isDir := strings.Cut(rest, "/")
+		if isDir {
+			children[child] = fileInfo{name: child, dir: true, modTime: f.modTime}
+		} else {
+			children[child] = fileInfo{name: child, size: int64(len(f.data)), modTime: f.modTime}
+		}
+	}
+	if len(children) == 0 && key != "" {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+
+	entries := make([]fs.FileInfo, 0, len(children))
+	for _, child := range children {
+		entries = append(entries, child)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return &openFile{
+		Reader:  bytes.NewReader(nil),
+		info:    fileInfo{name: path.Base("/" + key), dir: true},
+		entries: entries}, nil
+}
+
+// openFile is an open file, or a directory with its entries
+type openFile struct {
+	*bytes.Reader
+	info    fileInfo
+	entries []fs.FileInfo
+}
+
+func (f *openFile) Close() error {
+	return nil
+}
+
+func (f *openFile) Stat() (fs.FileInfo, error) {
+	return f.info, nil
+}
+
+func (f *openFile) Readdir(count int) ([]fs.FileInfo, error) {
+	if !f.info.dir {
+		return nil, &fs.PathError{Op: "readdir", Path: f.info.name, Err: fs.ErrInvalid}
+	}
+	if count <= 0 {
+		entries := f.entries
+		f.entries = nil
+		return entries, nil
+	}
+	if len(f.entries) == 0 {
+		return nil, io.EOF
+	}
+	n := min(count, len(f.entries))
+	entries := f.entries[:n]
+	f.entries = f.entries[n:]
+	return entries, nil
+}
+
+type fileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	dir     bool
+}
+
+func (i fileInfo) Name() string       { return i.name }
+func (i fileInfo) Size() int64        { return i.size }
+func (i fileInfo) ModTime() time.Time { return i.modTime }
+func (i fileInfo) IsDir() bool        { return i.dir }
+func (i fileInfo) Sys() any           { return nil }
+
+func (i fileInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0755
+	}
+	return 0644
+}
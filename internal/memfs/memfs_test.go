@@ -0,0 +1,90 @@
+package memfs
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFS(t *testing.T) {
+	m := New()
+	m.WriteFile("index.html", []byte("home"))
+	m.WriteFile("/docs/guide/index.html", []byte("guide"))
+	m.WriteFile("docs/intro.html", []byte("intro"))
+
+	f, err := m.Open("/docs/intro.html")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	content, _ := io.ReadAll(f)
+	info, _ := f.Stat()
+	if string(content) != "intro" || info.Name() != "intro.html" || info.Size() != 5 || info.IsDir() {
+		t.Errorf("unexpected file %q %+v", content, info)
+	}
+
+	dir, err := m.Open("/docs/")
+	if err != nil {
+		t.Fatalf("expected the directory to open, got %v", err)
+	}
+	entries, err := dir.Readdir(-1)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(entries) != 2 || entries[0].Name() != "guide" || !entries[0].IsDir() || entries[1].Name() != "intro.html" {
+		t.Errorf("unexpected directory entries %v", entries)
+	}
+
+	if _, err := m.Open("/missing"); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+	if _, err := m.Open("/"); err != nil {
+		t.Errorf("expected the root to open, got %v", err)
+	}
+
+	m.Remove("docs/intro.html")
+	if m.Exists("docs/intro.html") {
+		t.Error("expected the file to be removed")
+	}
+	if names := m.Names(); len(names) != 2 || names[0] != "docs/guide/index.html" || names[1] != "index.html" {
+		t.Errorf("unexpected names %v", names)
+	}
+
+	m.Clear()
+	if len(m.Names()) != 0 {
+		t.Error("expected no files after clear")
+	}
+	if _, err := m.Open("/"); err != nil {
+		t.Errorf("expected an empty root to open, got %v", err)
+	}
+}
+
+func TestFSReplace(t *testing.T) {
+	m := New()
+	m.WriteFile("old.html", []byte("old"))
+
+	next := New()
+	next.WriteFile("index.html", []byte("new"))
+	m.Replace(next)
+
+	if names := m.Names(); len(names) != 1 || names[0] != "index.html" {
+		t.Errorf("expected only the new files, got %v", names)
+	}
+	if len(next.Names()) != 0 {
+		t.Errorf("expected the replacing file system to be emptied, got %v", next.Names())
+	}
+}
+
+func TestFSServesHTTP(t *testing.T) {
+	m := New()
+	m.WriteFile("site.css", []byte("a{}"))
+
+	recorder := httptest.NewRecorder()
+	http.FileServer(m).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/site.css", nil))
+	if recorder.Code != http.StatusOK || recorder.Body.String() != "a{}" {
+		t.Errorf("expected the file to be served, got %d %q", recorder.Code, recorder.Body.String())
+	}
+	if contentType := recorder.Header().Get("Content-Type"); contentType != "text/css; charset=utf-8" {
+		t.Errorf("expected a css content type, got %s", contentType)
+	}
+}
@@ -0,0 +1,399 @@
+package minify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// minifiers by file extension
+var minifiers = map[string]func(string) (string, error){
+	".html":        HTML,
+	".htm":         HTML,
+	".css":         wrap(CSS),
+	".js":          wrap(JS),
+	".mjs":         wrap(JS),
+	".json":        JSON,
+	".webmanifest": JSON,
+	".svg":         wrap(SVG),
+}
+
+func wrap(fn func(string) string) func(string) (string, error) {
+	return func(s string) (string, error) { return fn(s), nil }
+}
+
+// IsSupported reports whether files with the extension can be minified.
+func IsSupported(ext string) bool {
+	_, ok := minifiers[strings.ToLower(ext)]
+	return ok
+}
+
+// Minify minifies content of the type given by a file extension, content of
+// other types is returned unchanged.
+func Minify(ext string, content string) (string, error) {
+	minifier, ok := minifiers[strings.ToLower(ext)]
+	if !ok {
+		return content, nil
+	}
+	return minifier(content)
+}
+
+// JSON removes the insignificant whitespace from json.
+func JSON(content string) (string, error) {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, []byte(content)); err != nil {
+		return "", fmt.Errorf("failed to minify json: %w", err)
+	}
+	return buf.String(), nil
+}
+
+var (
+	whitespacePattern   = regexp.MustCompile(`\s+`)
+	svgCommentPattern   = regexp.MustCompile(`(?s)<!--.*?-->`)
+	betweenTagsPattern  = regexp.MustCompile(`>\s+<`)
+	rawTextStartPattern = regexp.MustCompile(`(?i)<(pre|textarea|script|style)\b[^>]*>`)
+)
+
+// SVG removes comments and the whitespace between tags. Text inside
+// elements is kept as it is.
+func SVG(content string) string {
+	content = svgCommentPattern.ReplaceAllString(content, "")
+	content = betweenTagsPattern.ReplaceAllString(content, "><")
+	return strings.TrimSpace(content)
+}
+
+// HTML removes comments and collapses runs of whitespace to a single space.
+// The contents of pre and textarea elements are kept as they are, inline
+// scripts and styles are minified as javascript and css.
+func HTML(content string) (string, error) {
+	var sb strings.Builder
+	for len(content) > 0 {
+		loc := rawTextStartPattern.FindStringSubmatchIndex(content)
+		if loc == nil {
+			sb.WriteString(minifyHTMLText(content))
+			break
+		}
+
+		sb.WriteString(minifyHTMLText(content[:loc[0]]))
+		tag := strings.ToLower(content[loc[2]:loc[3]])
+		openTag := content[loc[0]:loc[1]]
+		rest := content[loc[1]:]
+
+		end := strings.Index(strings.ToLower(rest), "</"+tag)
+		if end == -1 {
+			end = len(rest)
+		}
+		body := rest[:end]
+
+		sb.WriteString(whitespacePattern.ReplaceAllString(openTag, " "))
+		switch {
+		case tag == "style":
+			sb.WriteString(CSS(body))
+		case tag == "script" && isJavaScript(openTag):
+			sb.WriteString(JS(body))
+		default:
+			sb.WriteString(body)
+		}
+		content = rest[end:]
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
+
+var scriptTypePattern = regexp.MustCompile(`(?i)\stype\s*=\s*["']?([^"'\s>]+)`)
+
+// isJavaScript reports whether a script tag holds javascript, scripts holding
+// json or templates are left alone
+func isJavaScript(openTag string) bool {
+	match := scriptTypePattern.FindStringSubmatch(openTag)
+	if match == nil {
+		return true
+	}
+	scriptType := strings.ToLower(match[1])
+	return scriptType == "module" || strings.Contains(scriptType, "javascript") || strings.Contains(scriptType, "ecmascript")
+}
+
+// minifyHTMLText minifies html that holds no raw text elements
+func minifyHTMLText(content string) string {
+	var sb strings.Builder
+	for {
+		start := strings.Index(content, "<!--")
+		if start == -1 {
+			break
+		}
+		end := strings.Index(content[start+4:], "-->")
+		if end == -1 {
+			break
+		}
+		end += start + 4 + 3
+
+		// conditional comments are read by old browsers
+		comment := content[start:end]
+		sb.WriteString(content[:start])
+		if strings.HasPrefix(comment, "<!--[if") {
+			sb.WriteString(comment)
+		}
+		content = content[end:]
+	}
+	sb.WriteString(content)
+	return whitespacePattern.ReplaceAllString(sb.String(), " ")
+}
+
+// CSS removes comments and the whitespace that has no meaning in css.
+// Strings are kept as they are.
+func CSS(content string) string {
+	out := make([]byte, 0, len(content))
+	pendingSpace := false
+
+	// whitespace around a colon only has no meaning in a declaration, in a
+	// selector "a :hover" is not "a:hover"
+	statementStart := true
+	inDeclaration := false
+	startStatement := func(i int) {
+		if statementStart {
+			inDeclaration = isDeclaration(content, i)
+			statementStart = false
+		}
+	}
+
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case c == '/' && i+1 < len(content) && content[i+1] == '*':
+			end := strings.Index(content[i+2:], "*/")
+			if end == -1 {
+				i = len(content)
+			} else {
+				i += end + 3
+			}
+			pendingSpace = true
+
+		case c == '"' || c == '\'':
+			startStatement(i)
+			end := stringEnd(content, i)
+			out = writeSpace(out, &pendingSpace, c, inDeclaration)
+			out = append(out, content[i:end]...)
+			i = end - 1
+
+		case isSpace(c):
+			pendingSpace = true
+
+		default:
+			startStatement(i)
+			// a semicolon before a closing brace is not needed
+			if c == '}' && len(out) > 0 && out[len(out)-1] == ';' {
+				out = out[:len(out)-1]
+			}
+			out = writeSpace(out, &pendingSpace, c, inDeclaration)
+			out = append(out, c)
+			statementStart = c == '{' || c == '}' || c == ';'
+		}
+	}
+	return string(out)
+}
+
+// isDeclaration reports whether the statement starting at i is a property
+// declaration, which ends with a semicolon or closing brace rather than
+// opening a block like a selector or an at-rule
+func isDeclaration(content string, i int) bool {
+	depth := 0
+	for ; i < len(content); i++ {
+		switch c := content[i]; c {
+		case '"', '\'':
+			i = stringEnd(content, i) - 1
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case '{':
+			if depth <= 0 {
+				return false
+			}
+		case ';', '}':
+			if depth <= 0 {
+				return true
+			}
+		}
+	}
+	return true
+}
+
+// writeSpace writes a pending space unless it is next to punctuation where
+// css does not need it
+func writeSpace(out []byte, pending *bool, next byte, inDeclaration bool) []byte {
+	if !*pending {
+		return out
+	}
+	*pending = false
+	punctuation := "{};,>~"
+	if inDeclaration {
+		punctuation += ":"
+	}
+	if len(out) == 0 || strings.IndexByte(punctuation, next) != -1 || strings.IndexByte(punctuation, out[len(out)-1]) != -1 {
+		return out
+	}
+	return append(out, ' ')
+}
+
+// JS removes comments, blank lines and the indentation of javascript. Line
+// breaks are kept so that automatic semicolon insertion is unchanged.
+func JS(content string) string {
+	minified, _ := JSLines(content)
+	return minified
+}
+
+// JSLines minifies javascript like JS and also returns, for each line of the
+// output, the 1-based line of the input it starts on.
+func JSLines(content string) (string, []int) {
+	out := make([]byte, 0, len(content))
+	lines := []int{}
+	lineStart := true
+	lastToken := byte(0)
+
+	// the input line of a token is found from the offsets of line breaks
+	breaks := []int{}
+	for i := 0; i < len(content); i++ {
+		if content[i] == '\n' {
+			breaks = append(breaks, i)
+		}
+	}
+	startToken := func(i int) {
+		if lineStart {
+			lines = append(lines, sort.SearchInts(breaks, i)+1)
+		}
+	}
+
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case c == '/' && i+1 < len(content) && content[i+1] == '/':
+			end := strings.IndexByte(content[i:], '\n')
+			if end == -1 {
+				i = len(content)
+			} else {
+				i += end - 1
+			}
+
+		case c == '/' && i+1 < len(content) && content[i+1] == '*':
+			end := strings.Index(content[i+2:], "*/")
+			comment := content[i:]
+			if end != -1 {
+				comment = content[i : i+end+4]
+			}
+			i += len(comment) - 1
+			if strings.Contains(comment, "\n") {
+				out = writeLineBreak(out, &lineStart)
+			} else if !lineStart {
+				out = append(out, ' ')
+			}
+
+		case c == '"' || c == '\'' || c == '`':
+			end := stringEnd(content, i)
+			startToken(i)
+			out = append(out, content[i:end]...)
+			i = end - 1
+			lineStart, lastToken = false, c
+
+		case c == '/' && isRegexStart(out, lastToken):
+			end := regexEnd(content, i)
+			startToken(i)
+			out = append(out, content[i:end]...)
+			i = end - 1
+			lineStart, lastToken = false, '/'
+
+		case c == '\n':
+			out = writeLineBreak(out, &lineStart)
+
+		case isSpace(c):
+			// keep a single space between tokens on a line
+			if !lineStart && i+1 < len(content) && !isSpace(content[i+1]) {
+				out = append(out, ' ')
+			}
+
+		default:
+			startToken(i)
+			out = append(out, c)
+			lineStart, lastToken = false, c
+		}
+	}
+	return strings.TrimSpace(string(out)), lines
+}
+
+func writeLineBreak(out []byte, lineStart *bool) []byte {
+	if !*lineStart {
+		for len(out) > 0 && out[len(out)-1] == ' ' {
+			out = out[:len(out)-1]
+		}
+		out = append(out, '\n')
+	}
+	*lineStart = true
+	return out
+}
+
+// isRegexStart reports whether a slash starts a regular expression literal
+// rather than a division, judged by the token before it
+func isRegexStart(written []byte, lastToken byte) bool {
+	if lastToken == 0 || strings.IndexByte("(,=:[!&|?{};+-*%<>~^", lastToken) != -1 {
+		return true
+	}
+	trimmed := strings.TrimRight(string(written[max(len(written)-8, 0):]), " \n")
+	for _, keyword := range []string{"return", "typeof", "case", "do", "else", "in", "of", "void", "yield", "throw"} {
+		if strings.HasSuffix(trimmed, keyword) {
+			before := strings.TrimSuffix(trimmed, keyword)
+			if before == "" || !isIdentByte(before[len(before)-1]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func regexEnd(content string, start int) int {
+	inClass := false
+	for i := start + 1; i < len(content); i++ {
+		switch content[i] {
+		case '\\':
+			i++
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '/':
+			if !inClass {
+				// include the flags
+				end := i + 1
+				for end < len(content) && isIdentByte(content[end]) {
+					end++
+				}
+				return end
+			}
+		case '\n':
+			return i
+		}
+	}
+	return len(content)
+}
+
+// stringEnd returns the index after the string starting at start
+func stringEnd(content string, start int) int {
+	quote := content[start]
+	for i := start + 1; i < len(content); i++ {
+		switch content[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1
+		}
+	}
+	return len(content)
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f'
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
@@ -0,0 +1,115 @@
+package minify
+
+import "testing"
+
+func TestHTML(t *testing.T) {
+	content := `<!DOCTYPE html>
+<html>
+  <!-- a comment -->
+  <!--[if IE]><p>old</p><![endif]-->
+  <body class="a   b">
+    <p>Hello,
+       <b>world</b> </p>
+    <pre>  keep
+    this  </pre>
+    <style>
+      body { color : red ; }
+    </style>
+    <script>
+      // greet
+      let  a = 1 / 2;
+    </script>
+    <script type="application/ld+json">{ "a": 1 }</script>
+  </body>
+</html>
+`
+	expected := `<!DOCTYPE html> <html> <!--[if IE]><p>old</p><![endif]--> <body class="a b"> <p>Hello, <b>world</b> </p> ` +
+		"<pre>  keep\n    this  </pre> <style>body{color:red}</style> <script>let a = 1 / 2;</script> " +
+		`<script type="application/ld+json">{ "a": 1 }</script> </body> </html>`
+
+	got, err := HTML(content)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+}
+
+func TestCSS(t *testing.T) {
+	content := `/* reset */
+a > b ,  c:hover {
+  content: " a  { } ";
+  margin: 0 auto;
+}
+@media screen and (max-width: 600px) {
+  a { width: calc(100% - 2px); }
+}
+`
+	expected := `a>b,c:hover{content:" a  { } ";margin:0 auto}@media screen and (max-width: 600px){a{width:calc(100% - 2px)}}`
+	if got := CSS(content); got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+}
+
+func TestCSSSelectors(t *testing.T) {
+	tests := map[string]string{
+		"a :hover { color : red }":       "a :hover{color:red}",
+		".a ~ .b { x: y }":               ".a~.b{x:y}",
+		".a > .b { x: y }":               ".a>.b{x:y}",
+		".a :is(.b) , .c { x: y }":       ".a :is(.b),.c{x:y}",
+		"a { & :hover { color : red } }": "a{& :hover{color:red}}",
+		"a { background : url(x;y) }":    "a{background:url(x;y)}",
+		"@page :first { margin : 0 }":    "@page :first{margin:0}",
+	}
+	for content, expected := range tests {
+		if got := CSS(content); got != expected {
+			t.Errorf("expected %s for %s, got %s", expected, content, got)
+		}
+	}
+}
+
+func TestJS(t *testing.T) {
+	content := `// leading comment
+function f(a,  b) {
+    /* inline */ return a / b; // divide
+}
+
+const re = /\/\/ not a comment/g;
+const s = "// not a comment";
+const t = ` + "`a\n  b`" + `
+/*
+ * block
+ */
+const x = 1
+`
+	expected := "function f(a, b) {\n" +
+		"return a / b;\n" +
+		"}\n" +
+		"const re = /\\/\\/ not a comment/g;\n" +
+		"const s = \"// not a comment\";\n" +
+		"const t = `a\n  b`\n" +
+		"const x = 1"
+	if got := JS(content); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestMinify(t *testing.T) {
+	got, err := Minify(".json", "{\n  \"a\": [1, 2]\n}")
+	if err != nil || got != `{"a":[1,2]}` {
+		t.Errorf("expected compact json, got %s (%v)", got, err)
+	}
+
+	got, _ = Minify(".svg", "<svg>\n  <!-- icon -->\n  <text> a  b </text>\n</svg>\n")
+	if got != `<svg><text> a  b </text></svg>` {
+		t.Errorf("expected minified svg, got %s", got)
+	}
+
+	if got, _ := Minify(".txt", "a  b"); got != "a  b" {
+		t.Errorf("expected unsupported content unchanged, got %s", got)
+	}
+	if _, err := Minify(".json", "{"); err == nil {
+		t.Error("expected invalid json to fail")
+	}
+}
@@ -0,0 +1,185 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"io"
+	"regexp"
+	"strings"
+	"text/template/parse"
+)
+
+const (
+	ContentTemplate = "content"
+
+	// wordsPerMinute is the reading speed used for the reading time
+	wordsPerMinute = 200
+	summaryWords   = 70
+)
+
+var headingPattern = regexp.MustCompile(`(?is)<h([1-6])([^>]*)>(.*?)</h[1-6]\s*>`)
+var idPattern = regexp.MustCompile(`(?i)\sid\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+var scriptPattern = regexp.MustCompile(`(?is)<script\b.*?</script\s*>`)
+var stylePattern = regexp.MustCompile(`(?is)<style\b.*?</style\s*>`)
+var tagPattern = regexp.MustCompile(`(?s)<[^>]*>`)
+var idInvalidPattern = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+
+// Heading is a heading in the page content, headings of a lower level that
+// follow it are its children.
+type Heading struct {
+	Level    int
+	ID       string
+	Title    string
+	Children []*Heading
+}
+
+// ContentInfo describes the rendered content of a page.
+type ContentInfo struct {
+	Headings    []*Heading
+	WordCount   int
+	ReadingTime int
+	Summary     string
+}
+
+// ParsePage parses page content, with frontmatter already removed, into a
+// clone of the layout template.
+func ParsePage(pageContent string, layoutTemplate *template.Template) (*template.Template, error) {
+	pageTemplate, err := layoutTemplate.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone layout: %w", err)
+	}
+	return pageTemplate.Parse(pageContent)
+}
+
+// RenderContent executes the content block of a parsed page on its own. The
+// page template is cloned so it can still be changed and executed after.
+func RenderContent(wr io.Writer, pageTemplate *template.Template, pageData any) (bool, error) {
+	if pageTemplate.Lookup(ContentTemplate) == nil {
+		return false, nil
+	}
+	content, err := pageTemplate.Clone()
+	if err != nil {
+		return false, fmt.Errorf("failed to clone page: %w", err)
+	}
+	return true, content.ExecuteTemplate(wr, ContentTemplate, pageData)
+}
+
+// ReplaceContent replaces the content block of a parsed page with html that
+// has already been rendered, so the layout writes it unchanged.
+func ReplaceContent(pageTemplate *template.Template, content string) error {
+	tree := &parse.Tree{Name: ContentTemplate, Root: &parse.ListNode{NodeType: parse.NodeList}}
+	tree.Root.Nodes = append(tree.Root.Nodes, &parse.TextNode{NodeType: parse.NodeText, Text: []byte(content)})
+	_, err := pageTemplate.AddParseTree(ContentTemplate, tree)
+	return err
+}
+
+// AnalyzeContent adds ids to the headings in rendered html that do not have
+// one, and returns the html with the heading tree, word count, reading time
+// in minutes and a plain text summary of the content.
+func AnalyzeContent(content string) (string, ContentInfo) {
+	info := ContentInfo{Headings: []*Heading{}}
+
+	// ids already in the content are kept, generated ones must not clash
+	used := map[string]bool{}
+	for _, match := range headingPattern.FindAllStringSubmatch(content, -1) {
+		if id := getID(match[2]); id != "" {
+			used[id] = true
+		}
+	}
+
+	stack := []*Heading{}
+	content = headingPattern.ReplaceAllStringFunc(content, func(tag string) string {
+		match := headingPattern.FindStringSubmatch(tag)
+		heading := &Heading{Level: int(match[1][0] - '0'), Title: PlainText(match[3])}
+
+		heading.ID = getID(match[2])
+		if heading.ID == "" {
+			heading.ID = uniqueID(headingID(heading.Title), used)
+			tag = fmt.Sprintf(`<h%s id="%s"%s>%s</h%s>`, match[1], html.EscapeString(heading.ID), match[2], match[3], match[1])
+		}
+
+		// nest the heading under the nearest heading with a lower level
+		for len(stack) > 0 && stack[len(stack)-1].Level >= heading.Level {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			info.Headings = append(info.Headings, heading)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, heading)
+		}
+		stack = append(stack, heading)
+		return tag
+	})
+
+	words := strings.Fields(PlainText(content))
+	info.WordCount = len(words)
+	info.ReadingTime = (len(words) + wordsPerMinute - 1) / wordsPerMinute
+	if len(words) > summaryWords {
+		info.Summary = strings.Join(words[:summaryWords], " ") + "…"
+	} else {
+		info.Summary = strings.Join(words, " ")
+	}
+	return content, info
+}
+
+// PlainText returns the text of html without tags, scripts and styles, with
+// runs of whitespace collapsed to a single space.
+func PlainText(content string) string {
+	content = scriptPattern.ReplaceAllString(content, " ")
+	content = stylePattern.ReplaceAllString(content, " ")
+	content = tagPattern.ReplaceAllString(content, " ")
+	return strings.Join(strings.Fields(html.UnescapeString(content)), " ")
+}
+
+// TableOfContents renders headings as nested lists of links.
+func TableOfContents(headings []*Heading) template.HTML {
+	if len(headings) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`<nav class="toc">`)
+	writeHeadings(&sb, headings)
+	sb.WriteString("</nav>")
+	return template.HTML(sb.String())
+}
+
+func writeHeadings(sb *strings.Builder, headings []*Heading) {
+	sb.WriteString("<ul>")
+	for _, heading := range headings {
+		fmt.Fprintf(sb, `<li><a href="#%s">%s</a>`, html.EscapeString(heading.ID), html.EscapeString(heading.Title))
+		if len(heading.Children) > 0 {
+			writeHeadings(sb, heading.Children)
+		}
+		sb.WriteString("</li>")
+	}
+	sb.WriteString("</ul>")
+}
+
+func getID(attrs string) string {
+	match := idPattern.FindStringSubmatch(attrs)
+	if match == nil {
+		return ""
+	}
+	return html.UnescapeString(match[1] + match[2] + match[3])
+}
+
+func headingID(title string) string {
+	id := idInvalidPattern.ReplaceAllString(strings.ToLower(title), "-")
+	id = strings.Trim(id, "-")
+	if id == "" {
+		return "section"
+	}
+	return id
+}
+
+func uniqueID(id string, used map[string]bool) string {
+	unique := id
+	for i := 1; used[unique]; i++ {
+		unique = fmt.Sprintf("%s-%d", id, i)
+	}
+	used[unique] = true
+	return unique
+}
@@ -0,0 +1,88 @@
+package render
+
+import (
+	"html/template"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeContent(t *testing.T) {
+	content := `<h1>Guide</h1><p>Intro &amp; more</p>
+<h2>Install</h2><h3 class="x">On <em>Linux</em></h3><h2 id="usage">Usage</h2><h2>Install</h2>
+<script>var hidden = 1</script>`
+
+	got, info := AnalyzeContent(content)
+
+	expected := `<h1 id="guide">Guide</h1><p>Intro &amp; more</p>
+<h2 id="install">Install</h2><h3 id="on-linux" class="x">On <em>Linux</em></h3><h2 id="usage">Usage</h2><h2 id="install-1">Install</h2>
+<script>var hidden = 1</script>`
+	if got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+
+	if len(info.Headings) != 1 || len(info.Headings[0].Children) != 3 {
+		t.Fatalf("expected one top heading with three children, got %+v", info.Headings)
+	}
+	install := info.Headings[0].Children[0]
+	if install.ID != "install" || len(install.Children) != 1 || install.Children[0].Title != "On Linux" {
+		t.Errorf("expected install heading with a nested linux heading, got %+v", install)
+	}
+
+	if info.WordCount != 9 {
+		t.Errorf("expected 9 words, got %d", info.WordCount)
+	}
+	if info.ReadingTime != 1 {
+		t.Errorf("expected 1 minute reading time, got %d", info.ReadingTime)
+	}
+	if info.Summary != "Guide Intro & more Install On Linux Usage Install" {
+		t.Errorf("unexpected summary %q", info.Summary)
+	}
+}
+
+func TestAnalyzeContentLongSummary(t *testing.T) {
+	_, info := AnalyzeContent("<p>" + strings.Repeat("word ", 450) + "</p>")
+
+	if info.WordCount != 450 || info.ReadingTime != 3 {
+		t.Errorf("expected 450 words and 3 minutes, got %d and %d", info.WordCount, info.ReadingTime)
+	}
+	if !strings.HasSuffix(info.Summary, "word…") || len(strings.Fields(info.Summary)) != summaryWords {
+		t.Errorf("expected summary truncated to %d words, got %q", summaryWords, info.Summary)
+	}
+}
+
+func TestTableOfContents(t *testing.T) {
+	_, info := AnalyzeContent(`<h2>A</h2><h3>B</h3><h2>C &lt; D</h2>`)
+
+	got := TableOfContents(info.Headings)
+	expected := template.HTML(`<nav class="toc"><ul><li><a href="#a">A</a><ul><li><a href="#b">B</a></li></ul></li><li><a href="#c-d">C &lt; D</a></li></ul></nav>`)
+	if got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+}
+
+func TestReplaceContent(t *testing.T) {
+	layout := template.Must(template.New("layout.tmpl").Parse(`<main>{{block "content" .}}{{end}}</main><p>{{.words}}</p>`))
+	page, err := ParsePage(`{{define "content"}}<h2>{{.title}}</h2>{{end}}`, layout)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var content strings.Builder
+	ok, err := RenderContent(&content, page, map[string]any{"title": "Hi"})
+	if !ok || err != nil {
+		t.Fatalf("expected content to render, got %v", err)
+	}
+	html, info := AnalyzeContent(content.String())
+	if err := ReplaceContent(page, html); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var output strings.Builder
+	if err := page.ExecuteTemplate(&output, "layout.tmpl", map[string]any{"words": info.WordCount}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := `<main><h2 id="hi">Hi</h2></main><p>1</p>`
+	if output.String() != expected {
+		t.Errorf("expected %s, got %s", expected, output.String())
+	}
+}
@@ -0,0 +1,150 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+const (
+	escapeXMLFunc  = "escapeXML"
+	escapeJSONFunc = "escapeJSON"
+)
+
+// textFormats maps the output extensions rendered with text/template to the
+// escaper applied to every action in the template. An empty escaper writes
+// values unchanged.
+var textFormats = map[string]string{
+	".xml":         escapeXMLFunc,
+	".rss":         escapeXMLFunc,
+	".atom":        escapeXMLFunc,
+	".svg":         escapeXMLFunc,
+	".json":        escapeJSONFunc,
+	".webmanifest": escapeJSONFunc,
+	".js":          escapeJSONFunc,
+	".txt":         "",
+	".css":         "",
+	".csv":         "",
+}
+
+var textFuncs = template.FuncMap{
+	escapeXMLFunc:  escapeXML,
+	escapeJSONFunc: escapeJSON,
+	"raw":          func(value any) string { return fmt.Sprint(value) },
+	"jsonify":      jsonify,
+}
+
+// funcs whose output is already safe for the target format are not escaped
+var safeTextFuncs = map[string]bool{
+	escapeXMLFunc:  true,
+	escapeJSONFunc: true,
+	"raw":          true,
+	"jsonify":      true,
+}
+
+// IsTextFormat reports whether pages with the output extension are rendered
+// with text/template rather than html/template.
+func IsTextFormat(ext string) bool {
+	_, ok := textFormats[ext]
+	return ok
+}
+
+// RenderText renders a standalone page for a non-html output format. Values
+// written by template actions are escaped for the format, the raw and jsonify
+// funcs can be used to write values verbatim or as json. The funcs are added
+// to the template and partials, by name, can be used with the template action.
+func RenderText(wr io.Writer, pageContent string, pageData any, ext string, funcs template.FuncMap, partials map[string]string) error {
+	escaper, ok := textFormats[ext]
+	if !ok {
+		return fmt.Errorf("unsupported output format %s", ext)
+	}
+
+	pageTemplate := template.New("page" + ext).Funcs(funcs).Funcs(textFuncs)
+
+	// add in a stable order so redefinitions are predictable
+	names := make([]string, 0, len(partials))
+	for name := range partials {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, err := pageTemplate.New(name).Parse(partials[name]); err != nil {
+			return fmt.Errorf("failed to parse partial %s: %w", name, err)
+		}
+	}
+
+	if _, err := pageTemplate.Parse(pageContent); err != nil {
+		return fmt.Errorf("failed to parse page template: %w", err)
+	}
+
+	if escaper != "" {
+		for _, t := range pageTemplate.Templates() {
+			if t.Tree != nil {
+				escapeActions(t.Tree, t.Tree.Root, escaper)
+			}
+		}
+	}
+
+	return pageTemplate.Execute(wr, pageData)
+}
+
+func escapeActions(tree *parse.Tree, node parse.Node, escaper string) {
+	switch node := node.(type) {
+	case *parse.ListNode:
+		if node == nil {
+			return
+		}
+		for _, child := range node.Nodes {
+			escapeActions(tree, child, escaper)
+		}
+	case *parse.IfNode:
+		escapeActions(tree, node.List, escaper)
+		escapeActions(tree, node.ElseList, escaper)
+	case *parse.RangeNode:
+		escapeActions(tree, node.List, escaper)
+		escapeActions(tree, node.ElseList, escaper)
+	case *parse.WithNode:
+		escapeActions(tree, node.List, escaper)
+		escapeActions(tree, node.ElseList, escaper)
+	case *parse.ActionNode:
+		// assignments write nothing to the output
+		if len(node.Pipe.Decl) > 0 || isSafePipe(node.Pipe) {
+			return
+		}
+		ident := parse.NewIdentifier(escaper).SetTree(tree).SetPos(node.Pos)
+		node.Pipe.Cmds = append(node.Pipe.Cmds, &parse.CommandNode{
+			NodeType: parse.NodeCommand,
+			Pos:      node.Pos,
+			Args:     []parse.Node{ident}})
+	}
+}
+
+func isSafePipe(pipe *parse.PipeNode) bool {
+	last := pipe.Cmds[len(pipe.Cmds)-1]
+	ident, ok := last.Args[0].(*parse.IdentifierNode)
+	return ok && safeTextFuncs[ident.Ident]
+}
+
+func escapeXML(value any) string {
+	var sb strings.Builder
+	template.HTMLEscape(&sb, []byte(fmt.Sprint(value)))
+	return sb.String()
+}
+
+func escapeJSON(value any) string {
+	// marshal as a json string and drop the surrounding quotes
+	encoded, _ := json.Marshal(fmt.Sprint(value))
+	return string(encoded[1 : len(encoded)-1])
+}
+
+func jsonify(value any) (string, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
@@ -0,0 +1,72 @@
+package render
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestRenderTextEscapesXML(t *testing.T) {
+	testTemplate := `<title>{{.title}}</title>{{range .tags}}<tag>{{.}}</tag>{{end}}{{$raw := "<b/>"}}{{raw $raw}}`
+	pageWriter := &strings.Builder{}
+
+	data := map[string]any{"title": "Fish & Chips", "tags": []string{"<a>"}}
+	err := RenderText(pageWriter, testTemplate, data, ".xml", nil, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expectedOutput := `<title>Fish &amp; Chips</title><tag>&lt;a&gt;</tag><b/>`
+	if output := pageWriter.String(); output != expectedOutput {
+		t.Errorf("expected %s, got %s", expectedOutput, output)
+	}
+}
+
+func TestRenderTextEscapesJSON(t *testing.T) {
+	testTemplate := `{"name": "{{.name}}", "icons": {{jsonify .icons}}}`
+	pageWriter := &strings.Builder{}
+
+	data := map[string]any{"name": `Say "hi"`, "icons": []string{"a.png"}}
+	err := RenderText(pageWriter, testTemplate, data, ".json", nil, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expectedOutput := `{"name": "Say \"hi\"", "icons": ["a.png"]}`
+	if output := pageWriter.String(); output != expectedOutput {
+		t.Errorf("expected %s, got %s", expectedOutput, output)
+	}
+}
+
+func TestRenderTextPlain(t *testing.T) {
+	pageWriter := &strings.Builder{}
+	err := RenderText(pageWriter, `Sitemap: {{.url}}`, map[string]any{"url": "https://a.b/?x=1&y=2"}, ".txt", nil, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if output := pageWriter.String(); output != "Sitemap: https://a.b/?x=1&y=2" {
+		t.Errorf("expected unescaped output, got %s", output)
+	}
+}
+
+func TestIsTextFormat(t *testing.T) {
+	if !IsTextFormat(".xml") || !IsTextFormat(".txt") {
+		t.Error("expected xml and txt to be text formats")
+	}
+	if IsTextFormat(".html") || IsTextFormat(".tmpl") {
+		t.Error("expected html and tmpl not to be text formats")
+	}
+}
+
+func TestRenderTextFuncsAndPartials(t *testing.T) {
+	pageWriter := &strings.Builder{}
+	funcs := template.FuncMap{"upper": strings.ToUpper}
+	partials := map[string]string{"entry.tmpl": `<entry>{{upper .}}</entry>`}
+	err := RenderText(pageWriter, `{{range .titles}}{{template "entry.tmpl" .}}{{end}}`, map[string]any{"titles": []string{"a & b"}}, ".xml", funcs, partials)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if output := pageWriter.String(); output != "<entry>A &amp; B</entry>" {
+		t.Errorf("expected the escaped partial output, got %s", output)
+	}
+}
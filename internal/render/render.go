@@ -14,21 +14,44 @@ const (
 )
 
 func RenderNamedTemplate(rd io.Reader, wr io.Writer, layout string, layoutTemplate *template.Template) error {
-	// consume reader and get page content
+	// consume reader and extract json frontmatter from page file
+	pageContent, pageData, err := ReadPage(rd)
+	if err != nil {
+		return err
+	}
+
+	return RenderPage(wr, pageContent, pageData, layout, layoutTemplate)
+}
+
+// ReadPage consumes a page file and returns its template content along with
+// the data declared in its json frontmatter.
+func ReadPage(rd io.Reader) (string, map[string]any, error) {
 	pageContentBytes, err := io.ReadAll(rd)
 	if err != nil {
-		return fmt.Errorf("failed to read page content: %w", err)
+		return "", nil, fmt.Errorf("failed to read page content: %w", err)
 	}
 
-	// extract json frontmatter from page file
 	pageContent, pageData, err := extractJsonFrontmatter(string(pageContentBytes))
 	if err != nil {
-		return fmt.Errorf("failed to extract json frontmatter: %w", err)
+		return "", nil, fmt.Errorf("failed to extract json frontmatter: %w", err)
 	}
 
-	// render template using layout
-	pageTemplate := template.Must(template.Must(layoutTemplate.Clone()).Parse(pageContent))
-	pageTemplate.ExecuteTemplate(wr, layout, pageData)
+	return pageContent, pageData, nil
+}
+
+// RenderPage renders page content, with frontmatter already removed, through
+// the named layout using pageData as the template data.
+func RenderPage(wr io.Writer, pageContent string, pageData any, layout string, layoutTemplate *template.Template) error {
+	pageTemplate, err := layoutTemplate.Clone()
+	if err != nil {
+		return fmt.Errorf("failed to clone layout %s: %w", layout, err)
+	}
+	if _, err := pageTemplate.Parse(pageContent); err != nil {
+		return fmt.Errorf("failed to parse page template: %w", err)
+	}
+	if err := pageTemplate.ExecuteTemplate(wr, layout, pageData); err != nil {
+		return fmt.Errorf("failed to execute layout %s: %w", layout, err)
+	}
 	return nil
 }
 
@@ -42,6 +42,17 @@ func TestRenderPage(t *testing.T) {
 	}
 }
 
+func TestRenderPageErrors(t *testing.T) {
+	layout := template.Must(template.New("test.tmpl").Parse(`<html>{{block "content" .}}{{end}}</html>`))
+
+	if err := RenderPage(&strings.Builder{}, `{{define "content"}}{{.title}`, nil, "test.tmpl", layout); err == nil {
+		t.Errorf("expected a parse error")
+	}
+	if err := RenderPage(&strings.Builder{}, ``, nil, "missing.tmpl", layout); err == nil {
+		t.Errorf("expected an error for a missing layout")
+	}
+}
+
 func TestExtractJsonFrontmatter(t *testing.T) {
 	pageContent := `{{/* {"title": "Test Page", "description": "This is a test page"} */}}`
 	expectedTitle := "Test Page"
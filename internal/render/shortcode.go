@@ -0,0 +1,278 @@
+package render
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
+)
+
+const (
+	openShortcodeTag  = "{{<"
+	closeShortcodeTag = ">}}"
+
+	// innerPlaceholder stands in for inner content while a shortcode template
+	// executes, so the inner content is spliced in without being escaped
+	innerPlaceholder = "\x00ditto-shortcode-inner\x00"
+)
+
+// ShortcodeTag is a single {{< name key="value" >}} or {{< /name >}} tag.
+type ShortcodeTag struct {
+	Name    string
+	Args    map[string]string
+	Closing bool
+	Start   int
+	End     int
+}
+
+// ShortcodeContext is the data a shortcode template is executed with. Page
+// is the data of the page as its layout gets it, with the frontmatter and
+// the page object, so .Page.title and .Page.Page.URL can be used.
+type ShortcodeContext struct {
+	Name  string
+	Args  map[string]string
+	Inner template.HTML
+	Page  any
+}
+
+type ShortcodeError struct {
+	File   string
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *ShortcodeError) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %v", e.File, e.Line, e.Column, e.Err)
+}
+
+func (e *ShortcodeError) Unwrap() error {
+	return e.Err
+}
+
+// ParseShortcodes finds the shortcode tags in page content.
+func ParseShortcodes(content string) ([]ShortcodeTag, error) {
+	tags := []ShortcodeTag{}
+	offset := 0
+	for {
+		start := strings.Index(content[offset:], openShortcodeTag)
+		if start == -1 {
+			return tags, nil
+		}
+		start += offset
+
+		end := strings.Index(content[start:], closeShortcodeTag)
+		if end == -1 {
+			return nil, shortcodeError(content, start, fmt.Errorf("shortcode is not closed with %s", closeShortcodeTag))
+		}
+		end += start + len(closeShortcodeTag)
+
+		tag, err := parseShortcodeTag(content[start+len(openShortcodeTag) : end-len(closeShortcodeTag)])
+		if err != nil {
+			return nil, shortcodeError(content, start, err)
+		}
+		tag.Start = start
+		tag.End = end
+		tags = append(tags, tag)
+		offset = end
+	}
+}
+
+func parseShortcodeTag(body string) (ShortcodeTag, error) {
+	body = strings.TrimSpace(body)
+	tag := ShortcodeTag{Args: map[string]string{}}
+	if strings.HasPrefix(body, "/") {
+		tag.Closing = true
+		tag.Name = strings.TrimSpace(body[1:])
+		if tag.Name == "" || strings.ContainsAny(tag.Name, " \t\n") {
+			return tag, fmt.Errorf("invalid closing shortcode %q", body)
+		}
+		return tag, nil
+	}
+
+	nameEnd := strings.IndexAny(body, " \t\r\n")
+	if nameEnd == -1 {
+		nameEnd = len(body)
+	}
+	tag.Name = body[:nameEnd]
+	if tag.Name == "" {
+		return tag, fmt.Errorf("shortcode has no name")
+	}
+
+	rest := strings.TrimSpace(body[nameEnd:])
+	for rest != "" {
+		key, value, found := strings.Cut(rest, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" || strings.ContainsAny(key, " \t\r\n\"") {
+			return tag, fmt.Errorf("shortcode %s arguments must be key=value pairs", tag.Name)
+		}
+
+		value = strings.TrimLeft(value, " \t\r\n")
+		if strings.HasPrefix(value, `"`) {
+			// find the closing quote, skipping escaped quotes
+			end := 1
+			for end < len(value) && (value[end] != '"' || value[end-1] == '\\') {
+				end++
+			}
+			if end == len(value) {
+				return tag, fmt.Errorf("shortcode %s argument %s is not closed", tag.Name, key)
+			}
+			unquoted, err := strconv.Unquote(value[:end+1])
+			if err != nil {
+				return tag, fmt.Errorf("shortcode %s argument %s: %w", tag.Name, key, err)
+			}
+			tag.Args[key] = unquoted
+			rest = strings.TrimSpace(value[end+1:])
+			continue
+		}
+
+		valueEnd := strings.IndexAny(value, " \t\r\n")
+		if valueEnd == -1 {
+			valueEnd = len(value)
+		}
+		tag.Args[key] = value[:valueEnd]
+		rest = strings.TrimSpace(value[valueEnd:])
+	}
+	return tag, nil
+}
+
+// ExpandShortcodes replaces the shortcodes in page content with the output of
+// their templates. A shortcode with a matching closing tag receives the
+// content between the tags, with its own shortcodes expanded, as Inner. The
+// output is spliced into the page template, so template delimiters written by
+// a shortcode are escaped while its inner content is kept as is.
+func ExpandShortcodes(content string, file string, lineOffset int, shortcodes map[string]*template.Template, pageData any) (string, error) {
+	tags, err := ParseShortcodes(content)
+	if err != nil {
+		return "", withLocation(err, file, lineOffset)
+	}
+	if len(tags) == 0 {
+		return content, nil
+	}
+
+	expander := &shortcodeExpander{content: content, tags: tags, shortcodes: shortcodes, pageData: pageData}
+	expanded, _, err := expander.expand(0, len(tags), "")
+	if err != nil {
+		return "", withLocation(err, file, lineOffset)
+	}
+	return expanded, nil
+}
+
+type shortcodeExpander struct {
+	content    string
+	tags       []ShortcodeTag
+	shortcodes map[string]*template.Template
+	pageData   any
+}
+
+// expand renders the content covered by tags[from:to], stopping at the
+// closing tag for parent. It returns the expanded content and the index of
+// the closing tag that ended it.
+func (e *shortcodeExpander) expand(from int, to int, parent string) (string, int, error) {
+	var sb strings.Builder
+	pos := e.position(from)
+	for i := from; i < to; i++ {
+		tag := e.tags[i]
+		sb.WriteString(e.content[pos:tag.Start])
+
+		if tag.Closing {
+			if tag.Name != parent {
+				return "", 0, shortcodeError(e.content, tag.Start, fmt.Errorf("unexpected closing shortcode %s", tag.Name))
+			}
+			return sb.String(), i, nil
+		}
+
+		shortcode, ok := e.shortcodes[tag.Name]
+		if !ok {
+			return "", 0, shortcodeError(e.content, tag.Start, fmt.Errorf("unknown shortcode %q", tag.Name))
+		}
+
+		inner := ""
+		next := i + 1
+		if e.hasClosingTag(i, to) {
+			expanded, closing, err := e.expand(i+1, to, tag.Name)
+			if err != nil {
+				return "", 0, err
+			}
+			inner = expanded
+			next = closing + 1
+		}
+
+		output, err := e.execute(shortcode, tag, inner)
+		if err != nil {
+			return "", 0, shortcodeError(e.content, tag.Start, err)
+		}
+		sb.WriteString(output)
+
+		pos = e.tags[next-1].End
+		i = next - 1
+	}
+
+	if parent != "" {
+		return "", 0, fmt.Errorf("shortcode %s is not closed", parent)
+	}
+	sb.WriteString(e.content[pos:])
+	return sb.String(), to, nil
+}
+
+func (e *shortcodeExpander) position(from int) int {
+	if from == 0 {
+		return 0
+	}
+	return e.tags[from-1].End
+}
+
+// hasClosingTag reports whether the shortcode at index i has a matching
+// closing tag, allowing for nested shortcodes of the same name.
+func (e *shortcodeExpander) hasClosingTag(i int, to int) bool {
+	depth := 0
+	name := e.tags[i].Name
+	for j := i + 1; j < to; j++ {
+		tag := e.tags[j]
+		if tag.Name != name {
+			continue
+		}
+		if !tag.Closing {
+			depth++
+			continue
+		}
+		if depth == 0 {
+			return true
+		}
+		depth--
+	}
+	return false
+}
+
+func (e *shortcodeExpander) execute(shortcode *template.Template, tag ShortcodeTag, inner string) (string, error) {
+	var output bytes.Buffer
+	err := shortcode.Execute(&output, ShortcodeContext{
+		Name:  tag.Name,
+		Args:  tag.Args,
+		Inner: template.HTML(innerPlaceholder),
+		Page:  e.pageData})
+	if err != nil {
+		return "", fmt.Errorf("failed to render shortcode %s: %w", tag.Name, err)
+	}
+
+	escaped := strings.ReplaceAll(output.String(), "{{", `{{"{{"}}`)
+	return strings.ReplaceAll(escaped, innerPlaceholder, inner), nil
+}
+
+func shortcodeError(content string, pos int, err error) error {
+	line := strings.Count(content[:pos], "\n") + 1
+	column := pos - strings.LastIndex(content[:pos], "\n")
+	return &ShortcodeError{Line: line, Column: column, Err: err}
+}
+
+func withLocation(err error, file string, lineOffset int) error {
+	var shortcodeErr *ShortcodeError
+	if errors.As(err, &shortcodeErr) {
+		shortcodeErr.File = file
+		shortcodeErr.Line += lineOffset
+		return shortcodeErr
+	}
+	return fmt.Errorf("%s: %w", file, err)
+}
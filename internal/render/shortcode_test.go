@@ -0,0 +1,86 @@
+package render
+
+import (
+	"errors"
+	"html/template"
+	"strings"
+	"testing"
+)
+
+func testShortcodes() map[string]*template.Template {
+	return map[string]*template.Template{
+		"youtube": template.Must(template.New("youtube").Parse(`<iframe src="https://www.youtube.com/embed/{{.Args.id}}" title="{{.Page.title}}"></iframe>`)),
+		"callout": template.Must(template.New("callout").Parse(`<aside class="{{.Args.type}}">{{.Inner}}</aside>`)),
+		"braces":  template.Must(template.New("braces").Parse(`{{.Args.text}}`)),
+	}
+}
+
+func TestParseShortcodes(t *testing.T) {
+	tags, err := ParseShortcodes(`a {{< figure src="/a b.png" caption="Say \"hi\"" width=300 >}} b {{< /figure >}}`)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(tags) != 2 {
+		t.Fatalf("expected 2 tags, got %d", len(tags))
+	}
+
+	args := tags[0].Args
+	if tags[0].Name != "figure" || args["src"] != "/a b.png" || args["caption"] != `Say "hi"` || args["width"] != "300" {
+		t.Errorf("unexpected tag %+v", tags[0])
+	}
+	if !tags[1].Closing || tags[1].Name != "figure" {
+		t.Errorf("expected closing figure tag, got %+v", tags[1])
+	}
+}
+
+func TestExpandShortcodes(t *testing.T) {
+	content := `{{define "content"}}
+{{< youtube id="abc123" >}}
+{{< callout type="warn" >}}Careful with {{.title}}! {{< callout type="nested" >}}inner{{< /callout >}}{{< /callout >}}
+{{< braces text="{{danger}}" >}}
+{{end}}`
+
+	expanded, err := ExpandShortcodes(content, "page.tmpl", 0, testShortcodes(), map[string]any{"title": "Ditto"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := `{{define "content"}}
+<iframe src="https://www.youtube.com/embed/abc123" title="Ditto"></iframe>
+<aside class="warn">Careful with {{.title}}! <aside class="nested">inner</aside></aside>
+{{"{{"}}danger}}
+{{end}}`
+	if expanded != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, expanded)
+	}
+
+	// the expanded content renders as a page
+	pageWriter := &strings.Builder{}
+	layout := template.Must(template.New("test.tmpl").Parse(`{{block "content" .}}{{end}}`))
+	if err := RenderPage(pageWriter, expanded, map[string]any{"title": "Ditto"}, "test.tmpl", layout); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(pageWriter.String(), "Careful with Ditto!") || !strings.Contains(pageWriter.String(), "{{danger}}") {
+		t.Errorf("unexpected rendered page %s", pageWriter.String())
+	}
+}
+
+func TestExpandShortcodesUnknown(t *testing.T) {
+	content := "line one\nline two {{< gallery >}}"
+
+	_, err := ExpandShortcodes(content, "pages/index.tmpl", 3, testShortcodes(), nil)
+	var shortcodeErr *ShortcodeError
+	if !errors.As(err, &shortcodeErr) {
+		t.Fatalf("expected shortcode error, got %v", err)
+	}
+	if err.Error() != `pages/index.tmpl:5:10: unknown shortcode "gallery"` {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestExpandShortcodesUnexpectedClosing(t *testing.T) {
+	_, err := ExpandShortcodes(`{{< /callout >}}`, "page.tmpl", 0, testShortcodes(), nil)
+	if err == nil || !strings.Contains(err.Error(), "unexpected closing shortcode callout") {
+		t.Fatalf("expected unexpected closing error, got %v", err)
+	}
+}
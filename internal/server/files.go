@@ -0,0 +1,173 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+)
+
+const (
+	TrailingSlashAdd    = "add"
+	TrailingSlashRemove = "remove"
+
+	indexFile = "index.html"
+)
+
+// notFoundPages are tried in order when no not found page is configured, a
+// pages/404.tmpl renders to /404/index.html unless given a url
+var notFoundPages = []string{"/404.html", "/404/index.html"}
+
+// fileHandler serves the files of a directory the way a static host does.
+// Directories are only served through their index.html, requests that match
+// no file fall back to the rewrite rules and then to the not found page.
+type fileHandler struct {
+	fs      http.FileSystem
+	options Options
+}
+
+func newFileHandler(fs http.FileSystem, options Options) *fileHandler {
+	return &fileHandler{fs: fs, options: options}
+}
+
+func (h *fileHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	urlPath := path.Clean("/" + r.URL.Path)
+	if strings.HasSuffix(r.URL.Path, "/") && urlPath != "/" {
+		urlPath += "/"
+	}
+
+	if name, redirect, ok := h.resolve(urlPath); ok {
+		if redirect != "" {
+			if r.URL.RawQuery != "" {
+				redirect += "?" + r.URL.RawQuery
+			}
+			http.Redirect(w, r, redirect, http.StatusMovedPermanently)
+			return
+		}
+		h.serveFile(w, r, name, http.StatusOK)
+		return
+	}
+
+	if rewrite, ok := matchRewrite(h.options.Rewrites, urlPath); ok {
+		if name, redirect, ok := h.resolve(rewrite.To); ok && redirect == "" {
+			h.serveFile(w, r, name, rewrite.status())
+			return
+		}
+	}
+
+	h.notFound(w, r)
+}
+
+// resolve finds the file for a clean url path, or the path to redirect to
+// when the url does not match the trailing slash option
+func (h *fileHandler) resolve(urlPath string) (string, string, bool) {
+	if strings.HasSuffix(urlPath, "/") {
+		if !h.isFile(urlPath + indexFile) {
+			return "", "", false
+		}
+		if h.options.TrailingSlash == TrailingSlashRemove && urlPath != "/" {
+			return "", strings.TrimSuffix(urlPath, "/"), true
+		}
+		return urlPath + indexFile, "", true
+	}
+
+	if h.isFile(urlPath) {
+		return urlPath, "", true
+	}
+	if h.isFile(urlPath + "/" + indexFile) {
+		if h.options.TrailingSlash == TrailingSlashRemove {
+			return urlPath + "/" + indexFile, "", true
+		}
+		return "", urlPath + "/", true
+	}
+
+	// ugly urls are served without their extension too
+	if h.isFile(urlPath + ".html") {
+		return urlPath + ".html", "", true
+	}
+	return "", "", false
+}
+
+func (h *fileHandler) isFile(name string) bool {
+	f, err := h.fs.Open(name)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	return err == nil && !info.IsDir()
+}
+
+func (h *fileHandler) notFound(w http.ResponseWriter, r *http.Request) {
+	pages := notFoundPages
+	if h.options.NotFound != "" {
+		pages = []string{path.Clean("/" + h.options.NotFound)}
+	}
+	for _, page := range pages {
+		if h.isFile(page) {
+			h.serveFile(w, r, page, http.StatusNotFound)
+			return
+		}
+	}
+	http.NotFound(w, r)
+}
+
+// serveFile writes a file with a status, successful responses go through
+// http.ServeContent for caching and range requests
+func (h *fileHandler) serveFile(w http.ResponseWriter, r *http.Request, name string, status int) {
+	f, err := h.fs.Open(name)
+	if errors.Is(err, os.ErrNotExist) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if status == http.StatusOK {
+		http.ServeContent(w, r, name, info.ModTime(), f)
+		return
+	}
+
+	if contentType := mime.TypeByExtension(path.Ext(name)); contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	w.WriteHeader(status)
+	if r.Method != http.MethodHead {
+		io.Copy(w, f)
+	}
+}
+
+// matchRewrite finds the rule for a path, rules ending in /* match every
+// path below them
+func matchRewrite(rewrites []Rewrite, urlPath string) (Rewrite, bool) {
+	for _, rewrite := range rewrites {
+		from := rewrite.From
+		if prefix, ok := strings.CutSuffix(from, "*"); ok {
+			if strings.HasPrefix(urlPath, prefix) || urlPath+"/" == prefix {
+				return rewrite, true
+			}
+			continue
+		}
+		if from == urlPath || from == strings.TrimSuffix(urlPath, "/") || from == urlPath+"/" {
+			return rewrite, true
+		}
+	}
+	return Rewrite{}, false
+}
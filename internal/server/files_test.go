@@ -0,0 +1,139 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+			t.Fatalf("failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write test file %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func serve(handler http.Handler, method string, target string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(method, target, nil))
+	return recorder
+}
+
+func TestFileHandler(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"index.html":       "home",
+		"about/index.html": "about",
+		"ugly.html":        "ugly",
+		"css/site.css":     "a{}",
+		"empty/.keep":      "",
+		"404/index.html":   "not found",
+	})
+	handler := newFileHandler(http.Dir(dir), Options{})
+
+	for _, test := range []struct {
+		target   string
+		status   int
+		body     string
+		location string
+	}{
+		{target: "/", status: http.StatusOK, body: "home"},
+		{target: "/about/", status: http.StatusOK, body: "about"},
+		{target: "/about?x=1", status: http.StatusMovedPermanently, location: "/about/?x=1"},
+		{target: "/ugly", status: http.StatusOK, body: "ugly"},
+		{target: "/css/site.css", status: http.StatusOK, body: "a{}"},
+		{target: "/empty/", status: http.StatusNotFound, body: "not found"},
+		{target: "/missing", status: http.StatusNotFound, body: "not found"},
+		{target: "/../index.html", status: http.StatusOK, body: "home"},
+	} {
+		recorder := serve(handler, http.MethodGet, test.target)
+		if recorder.Code != test.status {
+			t.Errorf("expected %d for %s, got %d", test.status, test.target, recorder.Code)
+		}
+		if test.body != "" && recorder.Body.String() != test.body {
+			t.Errorf("expected body %q for %s, got %q", test.body, test.target, recorder.Body.String())
+		}
+		if location := recorder.Header().Get("Location"); location != test.location {
+			t.Errorf("expected location %q for %s, got %q", test.location, test.target, location)
+		}
+	}
+
+	if recorder := serve(handler, http.MethodGet, "/missing"); recorder.Header().Get("Content-Type") != "text/html; charset=utf-8" {
+		t.Errorf("expected the not found page as html, got %s", recorder.Header().Get("Content-Type"))
+	}
+	if recorder := serve(handler, http.MethodPost, "/"); recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 for a post, got %d", recorder.Code)
+	}
+}
+
+func TestFileHandlerTrailingSlashRemove(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"index.html":       "home",
+		"about/index.html": "about",
+	})
+	handler := newFileHandler(http.Dir(dir), Options{TrailingSlash: TrailingSlashRemove})
+
+	if recorder := serve(handler, http.MethodGet, "/about"); recorder.Code != http.StatusOK || recorder.Body.String() != "about" {
+		t.Errorf("expected /about to be served, got %d %q", recorder.Code, recorder.Body.String())
+	}
+	recorder := serve(handler, http.MethodGet, "/about/")
+	if recorder.Code != http.StatusMovedPermanently || recorder.Header().Get("Location") != "/about" {
+		t.Errorf("expected /about/ to redirect to /about, got %d %s", recorder.Code, recorder.Header().Get("Location"))
+	}
+	if recorder := serve(handler, http.MethodGet, "/"); recorder.Code != http.StatusOK {
+		t.Errorf("expected the root to be served, got %d", recorder.Code)
+	}
+}
+
+func TestFileHandlerRewrites(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"index.html":     "home",
+		"app/index.html": "app",
+		"app/main.js":    "js",
+		"gone.html":      "gone",
+		"errors.html":    "custom not found",
+	})
+	handler := newFileHandler(http.Dir(dir), Options{
+		Rewrites: []Rewrite{
+			{From: "/app/*", To: "/app/index.html"},
+			{From: "/old", To: "/gone.html", Status: http.StatusGone},
+		},
+		NotFound: "errors.html",
+	})
+
+	for _, test := range []struct {
+		target string
+		status int
+		body   string
+	}{
+		{"/app/settings/profile", http.StatusOK, "app"},
+		{"/app/main.js", http.StatusOK, "js"},
+		{"/old/", http.StatusGone, "gone"},
+		{"/other", http.StatusNotFound, "custom not found"},
+	} {
+		recorder := serve(handler, http.MethodGet, test.target)
+		if recorder.Code != test.status || recorder.Body.String() != test.body {
+			t.Errorf("expected %d %q for %s, got %d %q", test.status, test.body, test.target, recorder.Code, recorder.Body.String())
+		}
+	}
+
+	if recorder := serve(handler, http.MethodHead, "/other"); recorder.Code != http.StatusNotFound || recorder.Body.Len() != 0 {
+		t.Errorf("expected an empty 404 for a head request, got %d %q", recorder.Code, recorder.Body.String())
+	}
+}
+
+func TestFileHandlerWithoutNotFoundPage(t *testing.T) {
+	handler := newFileHandler(http.Dir(writeFiles(t, map[string]string{"index.html": "home"})), Options{})
+	if recorder := serve(handler, http.MethodGet, "/missing"); recorder.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", recorder.Code)
+	}
+}
@@ -0,0 +1,201 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Mock answers requests to a route with a fixture, so pages that call an api
+// can be used while the api is not running. The body is json written as it
+// is, or read from BodyFile relative to the mocks directory.
+type Mock struct {
+	Method   string            `json:"method"`
+	Path     string            `json:"path"`
+	Status   int               `json:"status"`
+	Headers  map[string]string `json:"headers"`
+	Delay    string            `json:"delay"`
+	Body     json.RawMessage   `json:"body"`
+	BodyFile string            `json:"bodyFile"`
+
+	delay time.Duration
+}
+
+// withMocks answers requests matching a mock declared in the json files at
+// the top of dir, fixtures can be kept in subdirectories. The files are read
+// on every request so that changes are picked up without a restart.
+func withMocks(dir string, next http.Handler) http.Handler {
+	if dir == "" {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mocks, err := readMocks(dir)
+		if err != nil {
+			log.Printf("failed to read mocks: %v", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		mock, ok := matchMock(mocks, r.Method, r.URL.Path)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		serveMock(w, r, mock, dir)
+	})
+}
+
+func readMocks(dir string) ([]Mock, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	mocks := []Mock{}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		file := filepath.Join(dir, entry.Name())
+		declared, err := readMockFile(file)
+		if err != nil {
+			return nil, err
+		}
+		mocks = append(mocks, declared...)
+	}
+	return mocks, nil
+}
+
+// readMockFile reads a mock or a list of mocks
+func readMockFile(file string) ([]Mock, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	mocks := []Mock{}
+	content = bytes.TrimSpace(content)
+	if bytes.HasPrefix(content, []byte("[")) {
+		err = json.Unmarshal(content, &mocks)
+	} else {
+		var mock Mock
+		err = json.Unmarshal(content, &mock)
+		mocks = append(mocks, mock)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse mock file %s: %w", file, err)
+	}
+
+	for i := range mocks {
+		if err := checkMock(&mocks[i]); err != nil {
+			return nil, fmt.Errorf("mock file %s: %w", file, err)
+		}
+	}
+	return mocks, nil
+}
+
+func checkMock(mock *Mock) error {
+	if !strings.HasPrefix(mock.Path, "/") {
+		return fmt.Errorf("mock path %q must be an absolute path", mock.Path)
+	}
+	if mock.Status == 0 {
+		mock.Status = http.StatusOK
+	}
+	if mock.Status < 100 || mock.Status > 599 {
+		return fmt.Errorf("invalid status %d for mock %s", mock.Status, mock.Path)
+	}
+	if len(mock.Body) > 0 && mock.BodyFile != "" {
+		return fmt.Errorf("mock %s has both a body and a bodyFile", mock.Path)
+	}
+	if mock.Delay != "" {
+		delay, err := time.ParseDuration(mock.Delay)
+		if err != nil {
+			return fmt.Errorf("invalid delay %q for mock %s: %w", mock.Delay, mock.Path, err)
+		}
+		mock.delay = delay
+	}
+	return nil
+}
+
+// matchMock finds the first mock for a request, a method of "" matches any
+// method, :name matches a path segment and a trailing * the rest of the path
+func matchMock(mocks []Mock, method string, urlPath string) (Mock, bool) {
+	for _, mock := range mocks {
+		if mock.Method != "" && !strings.EqualFold(mock.Method, method) {
+			continue
+		}
+		if matchMockPath(mock.Path, urlPath) {
+			return mock, true
+		}
+	}
+	return Mock{}, false
+}
+
+func matchMockPath(pattern string, urlPath string) bool {
+	patternSegments := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(urlPath, "/"), "/")
+	for i, segment := range patternSegments {
+		if segment == "*" && i == len(patternSegments)-1 {
+			return true
+		}
+		if i >= len(pathSegments) {
+			return false
+		}
+		if !strings.HasPrefix(segment, ":") && segment != pathSegments[i] {
+			return false
+		}
+	}
+	return len(patternSegments) == len(pathSegments)
+}
+
+func serveMock(w http.ResponseWriter, r *http.Request, mock Mock, dir string) {
+	body := []byte(mock.Body)
+	contentType := "application/json"
+	if mock.BodyFile != "" {
+		file := filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(path.Clean("/"+mock.BodyFile), "/")))
+		content, err := os.ReadFile(file)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to read mock body %s: %v", mock.BodyFile, err), http.StatusInternalServerError)
+			return
+		}
+		body = content
+		if byExt := mime.TypeByExtension(filepath.Ext(file)); byExt != "" {
+			contentType = byExt
+		}
+	}
+
+	// the delay stands in for a slow api, it ends early if the client leaves
+	if mock.delay > 0 {
+		select {
+		case <-time.After(mock.delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if len(body) > 0 {
+		w.Header().Set("Content-Type", contentType)
+	}
+	for name, value := range mock.Headers {
+		w.Header().Set(name, value)
+	}
+
+	w.WriteHeader(mock.Status)
+	if r.Method != http.MethodHead {
+		w.Write(body)
+	}
+}
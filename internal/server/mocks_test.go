@@ -0,0 +1,124 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMatchMockPath(t *testing.T) {
+	for _, test := range []struct {
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{"/api/users", "/api/users", true},
+		{"/api/users", "/api/users/", true},
+		{"/api/users", "/api/users/1", false},
+		{"/api/users/:id", "/api/users/1", true},
+		{"/api/users/:id", "/api/users", false},
+		{"/api/*", "/api/users/1/posts", true},
+		{"/api/*", "/other", false},
+	} {
+		if got := matchMockPath(test.pattern, test.path); got != test.expected {
+			t.Errorf("expected %v for %s against %s, got %v", test.expected, test.path, test.pattern, got)
+		}
+	}
+}
+
+func TestWithMocks(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"users.json": `[
+			{"method": "GET", "path": "/api/users/:id", "body": {"id": 1, "name": "Ada"}, "headers": {"X-Mock": "true"}},
+			{"method": "POST", "path": "/api/users", "status": 201, "body": {"created": true}}
+		]`,
+		"error.json":            `{"path": "/api/broken", "status": 503}`,
+		"report.json":           `{"path": "/api/report", "bodyFile": "fixtures/report.csv"}`,
+		"fixtures/report.csv":   "a,b\n1,2\n",
+		"fixtures/ignored.json": `not a mock`,
+	})
+	handler := withMocks(dir, http.NotFoundHandler())
+
+	for _, test := range []struct {
+		method      string
+		target      string
+		status      int
+		body        string
+		contentType string
+	}{
+		{http.MethodGet, "/api/users/1", http.StatusOK, `{"id": 1, "name": "Ada"}`, "application/json"},
+		{http.MethodPost, "/api/users", http.StatusCreated, `{"created": true}`, "application/json"},
+		{http.MethodGet, "/api/broken", http.StatusServiceUnavailable, "", ""},
+		{http.MethodGet, "/api/report", http.StatusOK, "a,b\n1,2\n", "text/csv; charset=utf-8"},
+		{http.MethodDelete, "/api/users/1", http.StatusNotFound, "404 page not found\n", "text/plain; charset=utf-8"},
+	} {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(test.method, test.target, nil))
+		if recorder.Code != test.status || recorder.Body.String() != test.body {
+			t.Errorf("expected %d %q for %s %s, got %d %q", test.status, test.body, test.method, test.target, recorder.Code, recorder.Body.String())
+		}
+		if contentType := recorder.Header().Get("Content-Type"); contentType != test.contentType {
+			t.Errorf("expected content type %q for %s %s, got %q", test.contentType, test.method, test.target, contentType)
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/users/2", nil))
+	if recorder.Header().Get("X-Mock") != "true" {
+		t.Errorf("expected mock headers, got %v", recorder.Header())
+	}
+}
+
+func TestWithMocksDelay(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"slow.json": `{"path": "/api/slow", "delay": "50ms", "body": true}`,
+	})
+	handler := withMocks(dir, http.NotFoundHandler())
+
+	start := time.Now()
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/slow", nil))
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("expected the response to be delayed, took %v", elapsed)
+	}
+	if recorder.Body.String() != "true" {
+		t.Errorf("unexpected body %q", recorder.Body.String())
+	}
+
+	// a client that leaves does not wait for the delay
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/slow", nil).WithContext(ctx))
+	if recorder.Body.Len() != 0 {
+		t.Errorf("expected no body for a cancelled request, got %q", recorder.Body.String())
+	}
+}
+
+func TestReadMocksErrors(t *testing.T) {
+	for name, content := range map[string]string{
+		"relative path": `{"path": "api/users"}`,
+		"bad status":    `{"path": "/api", "status": 42}`,
+		"bad delay":     `{"path": "/api", "delay": "soon"}`,
+		"two bodies":    `{"path": "/api", "body": {}, "bodyFile": "a.json"}`,
+		"invalid json":  `{"path":`,
+	} {
+		dir := writeFiles(t, map[string]string{"mock.json": content})
+		if _, err := readMocks(dir); err == nil {
+			t.Errorf("expected an error for %s", name)
+		}
+
+		// broken mocks are skipped rather than breaking the site
+		recorder := httptest.NewRecorder()
+		withMocks(dir, http.NotFoundHandler()).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api", nil))
+		if recorder.Code != http.StatusNotFound {
+			t.Errorf("expected requests to pass through with %s, got %d", name, recorder.Code)
+		}
+	}
+
+	if mocks, err := readMocks(t.TempDir() + "/missing"); err != nil || len(mocks) != 0 {
+		t.Errorf("expected no mocks without a directory, got %v %v", mocks, err)
+	}
+}
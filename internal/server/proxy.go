@@ -0,0 +1,110 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+)
+
+// Proxy forwards requests under a path prefix to a local upstream, like an
+// api the static pages call. Headers with an empty value are removed, and
+// websocket upgrades are passed through.
+type Proxy struct {
+	Prefix string `json:"prefix"`
+	Target string `json:"target"`
+
+	// remove the prefix from the forwarded path, /api/users becomes /users
+	StripPrefix bool `json:"stripPrefix"`
+
+	// keep the host header of the request rather than the upstream host
+	PreserveHost bool `json:"preserveHost"`
+
+	Headers         map[string]string `json:"headers"`
+	ResponseHeaders map[string]string `json:"responseHeaders"`
+}
+
+// matches reports whether a path is below the prefix, /api matches /api and
+// /api/users but not /apis
+func (p Proxy) matches(urlPath string) bool {
+	prefix := strings.TrimSuffix(p.Prefix, "/")
+	return urlPath == prefix || strings.HasPrefix(urlPath, prefix+"/")
+}
+
+// withProxies sends requests matching a proxy to its upstream, the first
+// matching proxy is used and other requests go to next.
+func withProxies(proxies []Proxy, next http.Handler) (http.Handler, error) {
+	if len(proxies) == 0 {
+		return next, nil
+	}
+
+	handlers := make([]http.Handler, len(proxies))
+	for i, proxy := range proxies {
+		handler, err := newReverseProxy(proxy)
+		if err != nil {
+			return nil, err
+		}
+		handlers[i] = handler
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i, proxy := range proxies {
+			if proxy.matches(r.URL.Path) {
+				handlers[i].ServeHTTP(w, r)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	}), nil
+}
+
+func newReverseProxy(proxy Proxy) (*httputil.ReverseProxy, error) {
+	target, err := parseTarget(proxy.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	return &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			if proxy.StripPrefix {
+				r.Out.URL.Path = "/" + strings.TrimPrefix(strings.TrimPrefix(r.Out.URL.Path, strings.TrimSuffix(proxy.Prefix, "/")), "/")
+				r.Out.URL.RawPath = ""
+			}
+			r.SetURL(target)
+			r.SetXForwarded()
+			if proxy.PreserveHost {
+				r.Out.Host = r.In.Host
+			}
+			setHeaders(r.Out.Header, proxy.Headers)
+		},
+		ModifyResponse: func(resp *http.Response) error {
+			setHeaders(resp.Header, proxy.ResponseHeaders)
+			return nil
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			http.Error(w, fmt.Sprintf("proxy to %s failed: %v", proxy.Target, err), http.StatusBadGateway)
+		},
+	}, nil
+}
+
+func parseTarget(target string) (*url.URL, error) {
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy target %q: %w", target, err)
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid proxy target %q, use an http or https url", target)
+	}
+	return parsed, nil
+}
+
+func setHeaders(header http.Header, values map[string]string) {
+	for name, value := range values {
+		if value == "" {
+			header.Del(name)
+		} else {
+			header.Set(name, value)
+		}
+	}
+}
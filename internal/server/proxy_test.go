@@ -0,0 +1,135 @@
+package server
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestProxyMatches(t *testing.T) {
+	proxy := Proxy{Prefix: "/api/"}
+	for path, expected := range map[string]bool{"/api": true, "/api/": true, "/api/users": true, "/apis": false, "/": false} {
+		if proxy.matches(path) != expected {
+			t.Errorf("expected match %v for %s", expected, path)
+		}
+	}
+}
+
+func TestWithProxies(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "upstream")
+		w.Header().Set("X-Internal", "secret")
+		fmt.Fprintf(w, "%s %s host=%s auth=%s cookie=%s forwarded=%v",
+			r.URL.Path, r.URL.RawQuery, r.Host, r.Header.Get("Authorization"), r.Header.Get("Cookie"), r.Header.Get("X-Forwarded-For") != "")
+	}))
+	defer upstream.Close()
+
+	handler, err := withProxies([]Proxy{
+		{Prefix: "/api", Target: upstream.URL + "/v1", StripPrefix: true,
+			Headers:         map[string]string{"Authorization": "Bearer dev", "Cookie": ""},
+			ResponseHeaders: map[string]string{"X-Internal": "", "X-Proxied": "true"}},
+		{Prefix: "/auth", Target: upstream.URL, PreserveHost: true},
+	}, http.NotFoundHandler())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "http://example.test/api/users?page=2", nil)
+	request.Header.Set("Cookie", "session=1")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	expected := "/v1/users page=2 host=" + strings.TrimPrefix(upstream.URL, "http://") + " auth=Bearer dev cookie= forwarded=true"
+	if recorder.Body.String() != expected {
+		t.Errorf("expected %q, got %q", expected, recorder.Body.String())
+	}
+	if recorder.Header().Get("X-Internal") != "" || recorder.Header().Get("X-Proxied") != "true" {
+		t.Errorf("expected response headers to be rewritten, got %v", recorder.Header())
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://example.test/auth/login", nil))
+	if !strings.HasPrefix(recorder.Body.String(), "/auth/login  host=example.test ") {
+		t.Errorf("expected the path and host to be kept, got %q", recorder.Body.String())
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/index.html", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("expected other requests to pass through, got %d", recorder.Code)
+	}
+}
+
+func TestProxyUpstreamDown(t *testing.T) {
+	upstream := httptest.NewServer(http.NotFoundHandler())
+	target := upstream.URL
+	upstream.Close()
+
+	handler, err := withProxies([]Proxy{{Prefix: "/api", Target: target}}, http.NotFoundHandler())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/users", nil))
+	if recorder.Code != http.StatusBadGateway {
+		t.Errorf("expected 502, got %d", recorder.Code)
+	}
+}
+
+func TestProxyWebSocket(t *testing.T) {
+	// the upstream answers an upgrade and echoes a line back
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "websocket" {
+			http.Error(w, "expected an upgrade", http.StatusBadRequest)
+			return
+		}
+		conn, rw, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+		rw.Flush()
+		line, _ := rw.ReadString('\n')
+		rw.WriteString("echo " + line)
+		rw.Flush()
+	}))
+	defer upstream.Close()
+
+	handler, err := withProxies([]Proxy{{Prefix: "/ws", Target: upstream.URL}}, http.NotFoundHandler())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	proxy := httptest.NewServer(handler)
+	defer proxy.Close()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(proxy.URL, "http://"))
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer conn.Close()
+	fmt.Fprint(conn, "GET /ws/live HTTP/1.1\r\nHost: localhost\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+
+	reader := bufio.NewReader(conn)
+	response, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatalf("failed to read response: %v", err)
+	}
+	if response.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("expected 101, got %d", response.StatusCode)
+	}
+
+	fmt.Fprint(conn, "hello\n")
+	line, err := reader.ReadString('\n')
+	if err != nil && err != io.EOF {
+		t.Fatalf("failed to read echo: %v", err)
+	}
+	if line != "echo hello\n" {
+		t.Errorf("expected the upgraded connection to be passed through, got %q", line)
+	}
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const redirectsFile = "_redirects"
+
+type redirect struct {
+	to     string
+	status int
+}
+
+// withRedirects answers requests matching a rule in the netlify style
+// _redirects file of the served files with a real redirect. The file is read
+// on every request so that rebuilds are picked up without restarting the
+// server.
+func withRedirects(files http.FileSystem, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redirects, err := readRedirects(files, "/"+redirectsFile)
+		if err == nil {
+			if redirect, ok := matchRedirect(redirects, r.URL.Path); ok {
+				http.Redirect(w, r, redirect.to, redirect.status)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func readRedirects(files http.FileSystem, file string) (map[string]redirect, error) {
+	f, err := files.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseRedirects(f), nil
+}
+
+func parseRedirects(rd io.Reader) map[string]redirect {
+	redirects := map[string]redirect{}
+	scanner := bufio.NewScanner(rd)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// each rule is "from to [status]", invalid rules are ignored
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		status := http.StatusMovedPermanently
+		if len(fields) > 2 {
+			code, err := strconv.Atoi(strings.TrimSuffix(fields[2], "!"))
+			if err != nil || code < 300 || code > 399 {
+				continue
+			}
+			status = code
+		}
+		redirects[fields[0]] = redirect{to: fields[1], status: status}
+	}
+	return redirects
+}
+
+func matchRedirect(redirects map[string]redirect, path string) (redirect, bool) {
+	if redirect, ok := redirects[path]; ok {
+		return redirect, true
+	}
+
+	// rules match with or without a trailing slash
+	if strings.HasSuffix(path, "/") {
+		redirect, ok := redirects[strings.TrimSuffix(path, "/")]
+		return redirect, ok
+	}
+	redirect, ok := redirects[path+"/"]
+	return redirect, ok
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseRedirects(t *testing.T) {
+	redirects := parseRedirects(strings.NewReader(`
+# moved pages
+/old /new/
+/temp /elsewhere/ 302
+/broken
+/bad /x/ 200
+`))
+
+	if len(redirects) != 2 {
+		t.Fatalf("expected 2 redirects, got %d", len(redirects))
+	}
+	if redirects["/old"].to != "/new/" || redirects["/old"].status != http.StatusMovedPermanently {
+		t.Errorf("unexpected redirect for /old: %+v", redirects["/old"])
+	}
+	if redirects["/temp"].status != http.StatusFound {
+		t.Errorf("expected 302 for /temp, got %d", redirects["/temp"].status)
+	}
+}
+
+func TestWithRedirects(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, redirectsFile), []byte("/old/page /new/page/ 301\n"), 0644)
+	if err != nil {
+		t.Fatalf("failed to write redirects file: %v", err)
+	}
+
+	handler := withRedirects(http.Dir(dir), http.NotFoundHandler())
+	for _, path := range []string{"/old/page", "/old/page/"} {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		if recorder.Code != http.StatusMovedPermanently {
+			t.Errorf("expected 301 for %s, got %d", path, recorder.Code)
+		}
+		if location := recorder.Header().Get("Location"); location != "/new/page/" {
+			t.Errorf("expected location /new/page/ for %s, got %s", path, location)
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/other", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("expected unmatched request to pass through, got %d", recorder.Code)
+	}
+}
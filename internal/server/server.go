@@ -6,16 +6,57 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 )
 
-func StartDevelopmentServer(port int, dir string) error {
+// Options are read from the server section of the project config, so that
+// the development server behaves like the production host.
+type Options struct {
+	// add or remove the trailing slash of directory urls with a redirect,
+	// directories get a trailing slash by default
+	TrailingSlash string `json:"trailingSlash"`
+
+	// rewrites serve another file for paths that match no file, like a
+	// single page app falling back to /index.html
+	Rewrites []Rewrite `json:"rewrites"`
+
+	// the page served for paths that match no file or rewrite, 404.html or
+	// 404/index.html by default
+	NotFound string `json:"notFound"`
+
+	// proxies forward path prefixes to local upstreams, before any file
+	Proxies []Proxy `json:"proxies"`
+
+	// mocks declared in this directory answer before proxies and files
+	MocksDir string `json:"-"`
+}
+
+// Rewrite serves the file at To for requests to From, a From ending in /*
+// matches every path below it.
+type Rewrite struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Status int    `json:"status"`
+}
+
+func (r Rewrite) status() int {
+	if r.Status == 0 {
+		return http.StatusOK
+	}
+	return r.Status
+}
+
+// StartDevelopmentServer serves the rendered website from a file system,
+// http.Dir for the output directory or memory when serving without writing
+// to disk, until the process is interrupted.
+func StartDevelopmentServer(port int, files http.FileSystem, options Options) error {
 	done := make(chan os.Signal, 1)
 	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 
 	addr := fmt.Sprintf("localhost:%d", port)
-	srv, err := newDevelopmentServer(addr, dir)
+	srv, err := newDevelopmentServer(addr, files, options)
 	if err != nil {
 		return fmt.Errorf("failed to create server: %w", err)
 	}
@@ -38,12 +79,54 @@ func StartDevelopmentServer(port int, dir string) error {
 	return nil
 }
 
-func newDevelopmentServer(addr string, dir string) (*http.Server, error) {
-	if _, err := os.Stat(dir); os.IsNotExist(err) {
-		return nil, fmt.Errorf("directory does not exist: %s", dir)
+func newDevelopmentServer(addr string, files http.FileSystem, options Options) (*http.Server, error) {
+	root, err := files.Open("/")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open files to serve: %w", err)
+	}
+	root.Close()
+
+	if err := checkOptions(options); err != nil {
+		return nil, err
+	}
+
+	handler, err := withProxies(options.Proxies, withRedirects(files, newFileHandler(files, options)))
+	if err != nil {
+		return nil, err
 	}
 
 	return &http.Server{
 		Addr:    addr,
-		Handler: http.FileServer(http.Dir(dir))}, nil
+		Handler: withMocks(options.MocksDir, handler)}, nil
+}
+
+func checkOptions(options Options) error {
+	switch options.TrailingSlash {
+	case "", TrailingSlashAdd, TrailingSlashRemove:
+	default:
+		return fmt.Errorf("invalid trailingSlash %q, use %q or %q", options.TrailingSlash, TrailingSlashAdd, TrailingSlashRemove)
+	}
+
+	for _, rewrite := range options.Rewrites {
+		if !strings.HasPrefix(rewrite.From, "/") || !strings.HasPrefix(rewrite.To, "/") {
+			return fmt.Errorf("rewrite from %q to %q must use absolute paths", rewrite.From, rewrite.To)
+		}
+		if rewrite.Status != 0 && (rewrite.Status < 200 || rewrite.Status > 599) {
+			return fmt.Errorf("invalid status %d for rewrite from %s", rewrite.Status, rewrite.From)
+		}
+		// a rewrite serves a file, redirects go in the _redirects file
+		if rewrite.Status >= 300 && rewrite.Status < 400 {
+			return fmt.Errorf("rewrite from %s cannot use redirect status %d, use the _redirects file", rewrite.From, rewrite.Status)
+		}
+	}
+
+	for _, proxy := range options.Proxies {
+		if !strings.HasPrefix(proxy.Prefix, "/") {
+			return fmt.Errorf("proxy prefix %q must be an absolute path", proxy.Prefix)
+		}
+		if _, err := parseTarget(proxy.Target); err != nil {
+			return err
+		}
+	}
+	return nil
 }
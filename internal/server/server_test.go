@@ -0,0 +1,47 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestCheckOptions(t *testing.T) {
+	valid := []Options{
+		{},
+		{TrailingSlash: TrailingSlashAdd},
+		{TrailingSlash: TrailingSlashRemove, Rewrites: []Rewrite{{From: "/*", To: "/index.html"}}},
+		{Rewrites: []Rewrite{{From: "/old", To: "/gone.html", Status: 410}}},
+		{Proxies: []Proxy{{Prefix: "/api", Target: "http://localhost:3000"}}},
+	}
+	for _, options := range valid {
+		if err := checkOptions(options); err != nil {
+			t.Errorf("expected %+v to be valid, got %v", options, err)
+		}
+	}
+
+	invalid := []Options{
+		{TrailingSlash: "always"},
+		{Rewrites: []Rewrite{{From: "app/*", To: "/index.html"}}},
+		{Rewrites: []Rewrite{{From: "/old", To: "/gone.html", Status: 999}}},
+		{Rewrites: []Rewrite{{From: "/old", To: "/new.html", Status: 301}}},
+		{Proxies: []Proxy{{Prefix: "api", Target: "http://localhost:3000"}}},
+		{Proxies: []Proxy{{Prefix: "/api", Target: "localhost:3000"}}},
+	}
+	for _, options := range invalid {
+		if err := checkOptions(options); err == nil {
+			t.Errorf("expected %+v to be invalid", options)
+		}
+	}
+}
+
+func TestNewDevelopmentServer(t *testing.T) {
+	if _, err := newDevelopmentServer("localhost:0", http.Dir(t.TempDir()+"/missing"), Options{}); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+	if _, err := newDevelopmentServer("localhost:0", http.Dir(t.TempDir()), Options{TrailingSlash: "sometimes"}); err == nil {
+		t.Error("expected an error for invalid options")
+	}
+	if _, err := newDevelopmentServer("localhost:0", http.Dir(t.TempDir()), Options{}); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
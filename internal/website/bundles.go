@@ -0,0 +1,174 @@
+package website
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/eastcitysoftware/ditto/internal/bundle"
+)
+
+const (
+	DefaultAssetsDir = "assets"
+	assetFunc        = "asset"
+	sourceMapExt     = ".map"
+)
+
+// BundleConfig is a css or javascript entry point in the assets directory
+// and the path of the bundle in the output directory.
+type BundleConfig struct {
+	Entry  string `json:"entry"`
+	Output string `json:"output"`
+}
+
+// Bundle is a built bundle, URL includes the fingerprint when fingerprinting
+// is enabled.
+type Bundle struct {
+	Output string
+	URL    string
+
+	content   []byte
+	sourceMap []byte
+}
+
+// getBundles builds the configured bundles, keyed by their output path.
+func getBundles(config *WebsiteConfig) (map[string]*Bundle, error) {
+	bundles := map[string]*Bundle{}
+	for _, bundleConfig := range config.Bundles {
+		if bundleConfig.Entry == "" || bundleConfig.Output == "" {
+			return nil, fmt.Errorf("bundle needs an entry and an output, got %+v", bundleConfig)
+		}
+
+		built, err := buildBundle(bundleConfig, config)
+		if err != nil {
+			return nil, err
+		}
+		bundles[built.Output] = built
+	}
+	return bundles, nil
+}
+
+func buildBundle(bundleConfig BundleConfig, config *WebsiteConfig) (*Bundle, error) {
+	entry := filepath.Join(config.AssetsDir, filepath.FromSlash(bundleConfig.Entry))
+	options := bundle.Options{Root: config.AssetsDir, Minify: config.Minify}
+
+	var result *bundle.Result
+	var err error
+	switch strings.ToLower(path.Ext(bundleConfig.Entry)) {
+	case ".css":
+		result, err = bundle.CSS(entry, options)
+	case ".js", ".mjs":
+		result, err = bundle.JS(entry, options)
+	default:
+		return nil, fmt.Errorf("cannot bundle %s, entries must be css or javascript", bundleConfig.Entry)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to bundle %s: %w", bundleConfig.Entry, err)
+	}
+
+	output := strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(bundleConfig.Output)), "/")
+	name := output
+	if config.Fingerprint {
+		name = fingerprintName(output, result.Content)
+	}
+
+	// the source map sits next to the bundle and is linked from its last line
+	result.SourceMap.File = path.Base(name)
+	sourceMap, err := result.SourceMap.JSON()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode source map for %s: %w", bundleConfig.Entry, err)
+	}
+	comment := "//# sourceMappingURL=" + path.Base(name) + sourceMapExt
+	if path.Ext(output) == ".css" {
+		comment = "/*# sourceMappingURL=" + path.Base(name) + sourceMapExt + " */"
+	}
+
+	return &Bundle{
+		Output:    output,
+		URL:       "/" + name,
+		content:   []byte(result.Content + comment + "\n"),
+		sourceMap: sourceMap}, nil
+}
+
+// fingerprintName adds the start of the content hash to a file name, so
+// site.css becomes site.0123abcd.css
+func fingerprintName(name string, content string) string {
+	hash := sha256.Sum256([]byte(content))
+	ext := path.Ext(name)
+	return strings.TrimSuffix(name, ext) + "." + hex.EncodeToString(hash[:])[:8] + ext
+}
+
+// writeBundles writes bundles and their source maps to the output directory
+// and removes the fingerprinted files of earlier builds.
+func writeBundles(bundles map[string]*Bundle, config *WebsiteConfig) error {
+	for _, b := range bundles {
+		dst := filepath.Join(config.OutputDir, filepath.FromSlash(strings.TrimPrefix(b.URL, "/")))
+		if err := removeStaleBundles(b.Output, dst, config); err != nil {
+			return err
+		}
+
+		if err := writeOutput(config, dst, b.content); err != nil {
+			return err
+		}
+		if err := writeOutput(config, dst+sourceMapExt, b.sourceMap); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func removeStaleBundles(output string, current string, config *WebsiteConfig) error {
+	// memory is cleared by every full render
+	if config.Memory != nil {
+		return nil
+	}
+
+	ext := path.Ext(output)
+	base := strings.TrimSuffix(path.Base(output), ext)
+	stale := regexp.MustCompile(`^` + regexp.QuoteMeta(base) + `\.[0-9a-f]{8}` + regexp.QuoteMeta(ext) + `(\.map)?$`)
+
+	dir := filepath.Dir(current)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		// nothing to remove before the first build
+		return nil
+	}
+	for _, entry := range entries {
+		file := filepath.Join(dir, entry.Name())
+		if !stale.MatchString(entry.Name()) || file == current || file == current+sourceMapExt {
+			continue
+		}
+		if err := os.Remove(file); err != nil {
+			return fmt.Errorf("failed to remove stale bundle %s: %w", file, err)
+		}
+	}
+	return nil
+}
+
+// RenderBundles builds and writes the bundles without rendering pages, for
+// when only assets changed. Fingerprinted urls are not updated in pages.
+func RenderBundles(config *WebsiteConfig) error {
+	bundles, err := getBundles(config)
+	if err != nil {
+		return err
+	}
+	if err := writeBundles(bundles, config); err != nil {
+		return err
+	}
+	return saveOutputs(config)
+}
+
+// asset returns the url of a bundle by its configured output path
+func (w *Website) asset(output string) (string, error) {
+	output = strings.TrimPrefix(path.Clean("/"+output), "/")
+	b, ok := w.Bundles[output]
+	if !ok {
+		return "", fmt.Errorf("bundle %s is not configured", output)
+	}
+	return b.URL, nil
+}
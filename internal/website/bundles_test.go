@@ -0,0 +1,116 @@
+package website
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRenderBundles(t *testing.T) {
+	config := writeTestSite(t, map[string]string{
+		"pages/layouts/default.tmpl": `<link href="{{asset "css/site.css"}}"><script src="{{asset "js/app.js"}}"></script>`,
+		"pages/index.tmpl":           ``,
+		"assets/css/site.css":        "@import \"base.css\";\nbody {\n  color: red;\n}\n",
+		"assets/css/base.css":        "html {\n  margin: 0;\n}\n",
+		"assets/js/app.js":           "import { greet } from \"./greet.js\";\ngreet();\n",
+		"assets/js/greet.js":         "export function greet() {\n  console.log(\"hi\");\n}\n",
+	})
+	config.Minify = true
+	config.Fingerprint = true
+	config.Bundles = []BundleConfig{
+		{Entry: "css/site.css", Output: "css/site.css"},
+		{Entry: "js/app.js", Output: "/js/app.js"},
+	}
+
+	// a fingerprinted bundle from an earlier build
+	stale := filepath.Join(config.OutputDir, "css", "site.0123abcd.css")
+	if err := os.MkdirAll(filepath.Dir(stale), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(stale, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	website, err := Load(config)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := Render(website, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	output, err := os.ReadFile(filepath.Join(config.OutputDir, "index.html"))
+	if err != nil {
+		t.Fatalf("expected index.html to be written, got %v", err)
+	}
+	match := regexp.MustCompile(`^<link href="/css/site\.([0-9a-f]{8})\.css"><script src="/js/app\.[0-9a-f]{8}\.js"></script>$`).FindStringSubmatch(string(output))
+	if match == nil {
+		t.Fatalf("expected fingerprinted bundle urls, got %s", output)
+	}
+
+	css, err := os.ReadFile(filepath.Join(config.OutputDir, "css", "site."+match[1]+".css"))
+	if err != nil {
+		t.Fatalf("expected the css bundle to be written, got %v", err)
+	}
+	expected := "html{margin:0}\nbody{color:red}\n/*# sourceMappingURL=site." + match[1] + ".css.map */\n"
+	if string(css) != expected {
+		t.Errorf("expected %q, got %q", expected, css)
+	}
+
+	sourceMap, err := os.ReadFile(filepath.Join(config.OutputDir, "css", "site."+match[1]+".css.map"))
+	if err != nil {
+		t.Fatalf("expected the source map to be written, got %v", err)
+	}
+	if !strings.Contains(string(sourceMap), `"sources":["css/site.css","css/base.css"]`) {
+		t.Errorf("unexpected source map %s", sourceMap)
+	}
+
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("expected the stale bundle to be removed, got %v", err)
+	}
+}
+
+func TestRenderBundlesWithoutFingerprint(t *testing.T) {
+	config := writeTestSite(t, map[string]string{
+		"assets/app.js": "console.log(1);\n",
+	})
+	config.Bundles = []BundleConfig{{Entry: "app.js", Output: "app.js"}}
+
+	if err := RenderBundles(config); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	output, err := os.ReadFile(filepath.Join(config.OutputDir, "app.js"))
+	if err != nil {
+		t.Fatalf("expected app.js to be written, got %v", err)
+	}
+	if !strings.Contains(string(output), "\nconsole.log(1);\n") || !strings.HasSuffix(string(output), "//# sourceMappingURL=app.js.map\n") {
+		t.Errorf("unexpected bundle %s", output)
+	}
+	if _, err := os.Stat(filepath.Join(config.OutputDir, "app.js.map")); err != nil {
+		t.Errorf("expected the source map to be written, got %v", err)
+	}
+}
+
+func TestBundleErrors(t *testing.T) {
+	config := writeTestSite(t, map[string]string{
+		"assets/site.scss": "a {}",
+	})
+
+	config.Bundles = []BundleConfig{{Entry: "site.scss", Output: "site.css"}}
+	if _, err := getBundles(config); err == nil || !strings.Contains(err.Error(), "must be css or javascript") {
+		t.Errorf("expected an error for an unsupported entry, got %v", err)
+	}
+
+	config.Bundles = []BundleConfig{{Entry: "missing.css", Output: "site.css"}}
+	if _, err := getBundles(config); err == nil {
+		t.Error("expected an error for a missing entry")
+	}
+
+	website := &Website{Bundles: map[string]*Bundle{}}
+	if _, err := website.asset("site.css"); err == nil {
+		t.Error("expected an error for an unknown bundle")
+	}
+}
@@ -0,0 +1,107 @@
+package website
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"text/template"
+)
+
+const (
+	dataKey      = "data"
+	permalinkKey = "permalink"
+)
+
+func expandDataPage(pageFile string, layout string, pageData map[string]any, config *WebsiteConfig) ([]Page, error) {
+	source, ok := pageData[dataKey].(string)
+	if !ok || source == "" {
+		return nil, fmt.Errorf("data source for page %s must be a file name", pageFile)
+	}
+
+	pattern, ok := pageData[permalinkKey].(string)
+	if !ok || pattern == "" {
+		return nil, fmt.Errorf("data page %s requires a %s pattern", pageFile, permalinkKey)
+	}
+
+	permalink, err := template.New(pageFile).Option("missingkey=error").Parse(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse permalink for page %s: %w", pageFile, err)
+	}
+
+	dataFile, err := findDataFile(source, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load data for page %s: %w", pageFile, err)
+	}
+
+	items, err := readDataFile(dataFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load data for page %s: %w", pageFile, err)
+	}
+
+	pages := []Page{}
+	for i, item := range items {
+		itemData, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("item %d in %s is not an object", i, source)
+		}
+
+		var buf bytes.Buffer
+		if err := permalink.Execute(&buf, itemData); err != nil {
+			return nil, fmt.Errorf("failed to build permalink for item %d in %s: %w", i, source, err)
+		}
+
+		pageName, err := getPermalinkName(buf.String())
+		if err != nil {
+			return nil, fmt.Errorf("invalid permalink for item %d in %s: %w", i, source, err)
+		}
+
+		// item values take precedence over the template frontmatter
+		data := make(map[string]any, len(pageData)+len(itemData))
+		for key, value := range pageData {
+			data[key] = value
+		}
+		for key, value := range itemData {
+			data[key] = value
+		}
+
+		pages = append(pages, newPage(pageName, layout, pageFile, pageFile, data, config))
+	}
+
+	return pages, nil
+}
+
+func readDataFile(dataFile string) ([]any, error) {
+	content, err := os.ReadFile(dataFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []any
+	if err := json.Unmarshal(content, &items); err != nil {
+		return nil, fmt.Errorf("failed to parse %s as a json array: %w", dataFile, err)
+	}
+	return items, nil
+}
+
+func getPermalinkName(permalink string) (string, error) {
+	// a .. segment could leave the output directory, a.. in a name is fine
+	segments := strings.FieldsFunc(permalink, func(r rune) bool { return r == '/' || r == '\\' })
+	for _, segment := range segments {
+		if strings.TrimSpace(segment) == ".." {
+			return "", fmt.Errorf("permalink %q must not contain '..'", permalink)
+		}
+	}
+
+	// permalinks ending in a slash or without a file extension are written
+	// as pretty urls
+	permalink = strings.TrimSpace(permalink)
+	name := path.Clean("/" + permalink)
+	if strings.HasSuffix(permalink, "/") || path.Ext(name) == "" {
+		name = path.Join(name, "index.html")
+	}
+
+	return strings.TrimPrefix(name, "/"), nil
+}
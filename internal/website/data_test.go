@@ -0,0 +1,84 @@
+package website
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadDataPages(t *testing.T) {
+	config := writeTestSite(t, map[string]string{
+		"pages/layouts/default.tmpl": `<h1>{{.title}}</h1>{{block "content" .}}{{end}}`,
+		"pages/product.tmpl": `{{/* {"title": "Product", "data": "products.json", "permalink": "/products/{{.slug}}/"} */}}` +
+			`{{define "content"}}<p>{{.name}}</p>{{end}}`,
+		"data/products.json": `[{"slug": "chair", "name": "Chair"}, {"slug": "desk", "name": "Desk", "title": "Desk"}]`,
+	})
+
+	website, err := Load(config)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(website.Pages) != 2 {
+		t.Fatalf("expected 2 pages, got %d", len(website.Pages))
+	}
+
+	expectedURLs := []string{"/products/chair/", "/products/desk/"}
+	for i, page := range website.Pages {
+		if page.URL != expectedURLs[i] {
+			t.Errorf("expected url %s, got %s", expectedURLs[i], page.URL)
+		}
+	}
+
+	if err := Render(website, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	output, err := os.ReadFile(filepath.Join(config.OutputDir, "products", "desk", "index.html"))
+	if err != nil {
+		t.Fatalf("expected page to be rendered, got %v", err)
+	}
+	if string(output) != "<h1>Desk</h1><p>Desk</p>" {
+		t.Errorf("unexpected output %s", output)
+	}
+}
+
+func TestLoadDataPagesMissingPermalink(t *testing.T) {
+	config := writeTestSite(t, map[string]string{
+		"pages/layouts/default.tmpl": `{{block "content" .}}{{end}}`,
+		"pages/product.tmpl":         `{{/* {"data": "products.json"} */}}`,
+		"data/products.json":         `[{"slug": "chair"}]`,
+	})
+
+	_, err := Load(config)
+	if err == nil || !strings.Contains(err.Error(), "permalink") {
+		t.Fatalf("expected permalink error, got %v", err)
+	}
+}
+
+func TestGetPermalinkName(t *testing.T) {
+	tests := map[string]string{
+		"/products/chair/":    "products/chair/index.html",
+		"/products/chair":     "products/chair/index.html",
+		"products/chair.html": "products/chair.html",
+		"/":                   "index.html",
+		"/products//chair/./": "products/chair/index.html",
+		"/a..b/":              "a..b/index.html",
+		"/notes/v1..2.html":   "notes/v1..2.html",
+	}
+
+	for permalink, expected := range tests {
+		name, err := getPermalinkName(permalink)
+		if err != nil {
+			t.Fatalf("expected no error for %s, got %v", permalink, err)
+		}
+		if name != expected {
+			t.Errorf("expected %s for %s, got %s", expected, permalink, name)
+		}
+	}
+
+	for _, permalink := range []string{"/products/../../etc/", "..", `\..\etc`} {
+		if _, err := getPermalinkName(permalink); err == nil {
+			t.Errorf("expected error for permalink %s escaping the output directory", permalink)
+		}
+	}
+}
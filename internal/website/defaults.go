@@ -0,0 +1,75 @@
+package website
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const DefaultsFile = "_defaults.json"
+
+// defaultsCache holds the parsed defaults file of each directory, or nil for
+// directories without one, so each file is read once per load
+type defaultsCache map[string]map[string]any
+
+// applyDefaults merges the defaults files from the pages directory down to
+// the directory of the page, nearer directories override those above them
+// and the page frontmatter overrides them all.
+func applyDefaults(pageFile string, pageData map[string]any, pagesDir string, cache defaultsCache) (map[string]any, error) {
+	rel, err := filepath.Rel(pagesDir, filepath.Dir(pageFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine defaults for %s: %w", pageFile, err)
+	}
+
+	dirs := []string{pagesDir}
+	if rel != "." {
+		dir := pagesDir
+		for _, part := range strings.Split(filepath.ToSlash(rel), "/") {
+			dir = filepath.Join(dir, part)
+			dirs = append(dirs, dir)
+		}
+	}
+
+	merged := map[string]any{}
+	for _, dir := range dirs {
+		defaults, err := cache.read(dir)
+		if err != nil {
+			return nil, err
+		}
+		// defaults are cached for every page, so each page gets a copy
+		for key, value := range defaults {
+			merged[key] = copyValue(value)
+		}
+	}
+
+	for key, value := range pageData {
+		merged[key] = value
+	}
+	return merged, nil
+}
+
+func (c defaultsCache) read(dir string) (map[string]any, error) {
+	if defaults, ok := c[dir]; ok {
+		return defaults, nil
+	}
+
+	defaultsFile := filepath.Join(dir, DefaultsFile)
+	content, err := os.ReadFile(defaultsFile)
+	if errors.Is(err, os.ErrNotExist) {
+		c[dir] = nil
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read defaults file %s: %w", defaultsFile, err)
+	}
+
+	var defaults map[string]any
+	if err := json.Unmarshal(content, &defaults); err != nil {
+		return nil, fmt.Errorf("failed to parse defaults file %s: %w", defaultsFile, err)
+	}
+	c[dir] = defaults
+	return defaults, nil
+}
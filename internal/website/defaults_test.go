@@ -0,0 +1,80 @@
+package website
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestApplyDefaults(t *testing.T) {
+	config := writeTestSite(t, map[string]string{
+		"pages/_defaults.json":           `{"author": "Site", "section": "root", "layout": "default"}`,
+		"pages/blog/_defaults.json":      `{"author": "Blog Team", "section": "blog"}`,
+		"pages/blog/2025/_defaults.json": `{"section": "archive"}`,
+		"pages/blog/2025/post.tmpl":      ``,
+	})
+
+	pageFile := filepath.Join(config.PagesDir, "blog", "2025", "post.tmpl")
+	pageData, err := applyDefaults(pageFile, map[string]any{"author": "Jo"}, config.PagesDir, defaultsCache{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := map[string]any{"author": "Jo", "section": "archive", "layout": "default"}
+	if len(pageData) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, pageData)
+	}
+	for key, value := range expected {
+		if pageData[key] != value {
+			t.Errorf("expected %s to be %v, got %v", key, value, pageData[key])
+		}
+	}
+}
+
+func TestApplyDefaultsCopiesValues(t *testing.T) {
+	config := writeTestSite(t, map[string]string{
+		"pages/_defaults.json": `{"tags": ["news"], "social": {"links": ["rss"]}}`,
+	})
+
+	cache := defaultsCache{}
+	pageFile := filepath.Join(config.PagesDir, "post.tmpl")
+	first, err := applyDefaults(pageFile, map[string]any{}, config.PagesDir, cache)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	second, err := applyDefaults(pageFile, map[string]any{}, config.PagesDir, cache)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// changing the data of one page leaves the other page alone
+	first["tags"].([]any)[0] = "changed"
+	first["social"].(map[string]any)["links"].([]any)[0] = "changed"
+	if second["tags"].([]any)[0] != "news" || second["social"].(map[string]any)["links"].([]any)[0] != "rss" {
+		t.Errorf("expected pages not to share default values, got %v", second)
+	}
+}
+
+func TestLoadLayoutFromDefaults(t *testing.T) {
+	config := writeTestSite(t, map[string]string{
+		"pages/layouts/default.tmpl": `{{block "content" .}}{{end}}`,
+		"pages/layouts/post.tmpl":    `{{.author}}{{block "content" .}}{{end}}`,
+		"pages/blog/_defaults.json":  `{"layout": "post", "author": "Blog Team"}`,
+		"pages/blog/first.tmpl":      ``,
+		"pages/blog/second.tmpl":     `{{/* {"layout": "default.tmpl"} */}}`,
+	})
+
+	website, err := Load(config)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := map[string]string{"first.tmpl": "post.tmpl", "second.tmpl": "default.tmpl"}
+	for _, page := range website.Pages {
+		if page.Layout != expected[filepath.Base(page.InputPath)] {
+			t.Errorf("expected layout %s for %s, got %s", expected[filepath.Base(page.InputPath)], page.InputPath, page.Layout)
+		}
+		if page.Data["author"] != "Blog Team" {
+			t.Errorf("expected author default for %s, got %v", page.InputPath, page.Data["author"])
+		}
+	}
+}
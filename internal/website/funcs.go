@@ -0,0 +1,33 @@
+package website
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/eastcitysoftware/ditto/internal/highlight"
+)
+
+// TemplateFuncs are the functions available to layouts, partials, shortcodes
+// and pages. T, image and asset are replaced for each page when it is
+// rendered, elsewhere T returns the key and image and asset fail.
+var TemplateFuncs = template.FuncMap{
+	"highlight":   highlightFunc,
+	translateFunc: func(key string, args ...any) string { return key },
+	imageFunc: func(name string) (*Image, error) {
+		return nil, fmt.Errorf("image %s can only be used in pages and layouts", name)
+	},
+	assetFunc: func(output string) (string, error) {
+		return "", fmt.Errorf("asset %s can only be used in pages and layouts", output)
+	},
+}
+
+// highlightFunc highlights code for use in templates, options are written as
+// "linenos=true,hl_lines=2-4".
+func highlightFunc(code string, lang string, options ...string) (template.HTML, error) {
+	parsed, err := highlight.ParseOptions(strings.Join(options, ","))
+	if err != nil {
+		return "", err
+	}
+	return template.HTML(highlight.Highlight(code, lang, parsed)), nil
+}
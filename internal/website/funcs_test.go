@@ -0,0 +1,41 @@
+package website
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderHighlight(t *testing.T) {
+	config := writeTestSite(t, map[string]string{
+		"pages/layouts/default.tmpl": `{{block "content" .}}{{end}}`,
+		"pages/index.tmpl": `{{/* {"title": "Home"} */}}
+{{define "content"}}{{highlight "x := 1" "go" "hl_lines=1"}}<pre><code class="language-js">let a = 1</code></pre>
+` + "```python\ndef f():\n    pass\n```" + `
+{{end}}`,
+	})
+	config.HighlightCodeBlocks = true
+
+	website, err := Load(config)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := Render(website, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	output, err := os.ReadFile(filepath.Join(config.OutputDir, "index.html"))
+	if err != nil {
+		t.Fatalf("expected page to be rendered, got %v", err)
+	}
+
+	for _, want := range []string{
+		`<span class="line hl"><span class="cl">x <span class="o">:=</span>`,
+		`<code class="language-js" data-lang="js"><span class="k">let</span>`,
+		`<code class="language-python" data-lang="python"><span class="k">def</span>`,
+	} {
+		if !strings.Contains(string(output), want) {
+			t.Errorf("expected %q in %s", want, output)
+		}
+	}
+}
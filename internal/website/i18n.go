@@ -0,0 +1,217 @@
+package website
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"html/template"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	DefaultI18nDir    = "i18n"
+	translationKeyKey = "translationKey"
+	translateFunc     = "T"
+)
+
+// Language is a language of a multilingual site. Pages in languages other
+// than the default are written under a prefix, the language code unless the
+// config sets another.
+type Language struct {
+	Code   string `json:"code"`
+	Name   string `json:"name"`
+	Prefix string `json:"prefix"`
+}
+
+// defaultLanguage is the first language in the config, or none for sites
+// with a single language
+func (c *WebsiteConfig) defaultLanguage() string {
+	if len(c.Languages) == 0 {
+		return ""
+	}
+	return c.Languages[0].Code
+}
+
+func (c *WebsiteConfig) getLanguage(code string) (Language, bool) {
+	for i, language := range c.Languages {
+		if language.Code != code {
+			continue
+		}
+		if language.Prefix == "" && i > 0 {
+			language.Prefix = language.Code
+		}
+		return language, true
+	}
+	return Language{}, false
+}
+
+// getPageLanguage determines the language of a page from a language
+// directory at the top of the pages directory, like fr/about.tmpl, or from
+// a suffix on the file name, like about.fr.tmpl. It also returns the page
+// file with the language removed, which names the page in every language.
+func getPageLanguage(pageFile string, config *WebsiteConfig) (string, string) {
+	if len(config.Languages) == 0 {
+		return "", pageFile
+	}
+
+	rel, err := filepath.Rel(config.PagesDir, pageFile)
+	if err != nil {
+		return config.defaultLanguage(), pageFile
+	}
+	rel = filepath.ToSlash(rel)
+
+	if dir, rest, found := strings.Cut(rel, "/"); found {
+		if _, ok := config.getLanguage(dir); ok {
+			return dir, filepath.Join(config.PagesDir, filepath.FromSlash(rest))
+		}
+	}
+
+	name := strings.TrimSuffix(rel, TmplExtension)
+	if ext := path.Ext(name); ext != "" {
+		if _, ok := config.getLanguage(ext[1:]); ok {
+			neutral := strings.TrimSuffix(name, ext) + TmplExtension
+			return ext[1:], filepath.Join(config.PagesDir, filepath.FromSlash(neutral))
+		}
+	}
+	return config.defaultLanguage(), pageFile
+}
+
+// localizePage sets the language of a page and moves its output under the
+// prefix of the language. Translations of a page share the name the page
+// has without a prefix, unless the frontmatter sets a translationKey.
+func localizePage(page *Page, language string, config *WebsiteConfig) {
+	page.Language = language
+	page.TranslationKey = filepath.ToSlash(page.Name)
+	if key, ok := page.Data[translationKeyKey].(string); ok && key != "" {
+		page.TranslationKey = key
+	}
+
+	lang, ok := config.getLanguage(language)
+	if !ok || lang.Prefix == "" {
+		return
+	}
+	page.Name = filepath.Join(lang.Prefix, page.Name)
+	page.URL = "/" + lang.Prefix + page.URL
+	page.OutputPath = filepath.Join(config.OutputDir, page.Name)
+}
+
+// getTranslationTables reads the translation strings of each language from
+// i18n/<code>.json in the project and then its themes, nested objects are
+// flattened into keys joined with dots.
+func getTranslationTables(config *WebsiteConfig) (map[string]map[string]string, error) {
+	tables := map[string]map[string]string{}
+	dirs := []string{config.I18nDir}
+	for _, theme := range config.Themes {
+		dirs = append(dirs, filepath.Join(theme, DefaultI18nDir))
+	}
+
+	for _, language := range config.Languages {
+		table := map[string]string{}
+		for _, dir := range dirs {
+			if dir == "" {
+				continue
+			}
+
+			file := filepath.Join(dir, language.Code+".json")
+			content, err := os.ReadFile(file)
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to read translations %s: %w", file, err)
+			}
+
+			var values map[string]any
+			if err := json.Unmarshal(content, &values); err != nil {
+				return nil, fmt.Errorf("failed to parse translations %s: %w", file, err)
+			}
+			if err := flattenTranslations(values, "", table); err != nil {
+				return nil, fmt.Errorf("invalid translations %s: %w", file, err)
+			}
+		}
+		tables[language.Code] = table
+	}
+	return tables, nil
+}
+
+// flattenTranslations adds strings to the table unless a directory earlier
+// in the lookup order already set them
+func flattenTranslations(values map[string]any, prefix string, table map[string]string) error {
+	for key, value := range values {
+		switch value := value.(type) {
+		case string:
+			if _, exists := table[prefix+key]; !exists {
+				table[prefix+key] = value
+			}
+		case map[string]any:
+			if err := flattenTranslations(value, prefix+key+".", table); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("translation %s%s must be a string or an object", prefix, key)
+		}
+	}
+	return nil
+}
+
+// translate returns the T template func for a language. Strings missing from
+// the language fall back to the default language and then to the key itself,
+// arguments are formatted into the string like printf.
+func (w *Website) translate(language string) func(key string, args ...any) string {
+	return func(key string, args ...any) string {
+		value, ok := w.TranslationTables[language][key]
+		if !ok {
+			value, ok = w.TranslationTables[w.Config.defaultLanguage()][key]
+		}
+		if !ok {
+			value = key
+		}
+		if len(args) > 0 {
+			return fmt.Sprintf(value, args...)
+		}
+		return value
+	}
+}
+
+// getTranslations returns the other language versions of a page
+func getTranslations(pages []Page, page *Page) []*Page {
+	translations := []*Page{}
+	if page.Language == "" {
+		return translations
+	}
+	for i := range pages {
+		other := &pages[i]
+		if other != page && other.Language != page.Language && other.TranslationKey == page.TranslationKey {
+			translations = append(translations, other)
+		}
+	}
+	sort.Slice(translations, func(i, j int) bool {
+		return translations[i].Language < translations[j].Language
+	})
+	return translations
+}
+
+// hreflangLinks returns the alternate links of a page and its translations,
+// with the default language version as the x-default.
+func hreflangLinks(page *Page, translations []*Page, config *WebsiteConfig) template.HTML {
+	if len(translations) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	for _, version := range append([]*Page{page}, translations...) {
+		fmt.Fprintf(&sb, `<link rel="alternate" hreflang="%s" href="%s">`,
+			html.EscapeString(version.Language), html.EscapeString(version.URL))
+	}
+	for _, version := range append([]*Page{page}, translations...) {
+		if version.Language == config.defaultLanguage() {
+			fmt.Fprintf(&sb, `<link rel="alternate" hreflang="x-default" href="%s">`, html.EscapeString(version.URL))
+		}
+	}
+	return template.HTML(sb.String())
+}
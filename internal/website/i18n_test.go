@@ -0,0 +1,98 @@
+package website
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRenderLanguages(t *testing.T) {
+	config := writeTestSite(t, map[string]string{
+		"pages/layouts/default.tmpl": `<html lang="{{.Page.Language.Code}}">{{.Page.Hreflang}}` +
+			`{{T "greeting" .title}} {{T "nav.home"}} {{T "missing"}}|{{range .Page.Translations}}{{.URL}} {{end}}` +
+			`|{{range .Page.Menus.main}}{{.Name}} {{end}}</html>`,
+		"pages/about.tmpl":       `{{/* {"title": "About", "menu": "main"} */}}`,
+		"pages/about.fr.tmpl":    `{{/* {"title": "À propos", "menu": "main"} */}}`,
+		"pages/fr/contact.tmpl":  `{{/* {"title": "Contact"} */}}`,
+		"pages/blog/post.tmpl":   `{{/* {"title": "Post", "translationKey": "post"} */}}`,
+		"pages/fr/blog/art.tmpl": `{{/* {"title": "Article", "translationKey": "post"} */}}`,
+		"i18n/en.json":           `{"greeting": "Hello %s", "nav": {"home": "Home"}}`,
+		"i18n/fr.json":           `{"greeting": "Bonjour %s"}`,
+	})
+	config.I18nDir = filepath.Join(filepath.Dir(config.PagesDir), DefaultI18nDir)
+	config.Languages = []Language{{Code: "en", Name: "English"}, {Code: "fr", Name: "Français"}}
+
+	website, err := Load(config)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := Render(website, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	aboutLinks := `<link rel="alternate" hreflang="en" href="/about/"><link rel="alternate" hreflang="fr" href="/fr/about/">` +
+		`<link rel="alternate" hreflang="x-default" href="/about/">`
+	frAboutLinks := `<link rel="alternate" hreflang="fr" href="/fr/about/"><link rel="alternate" hreflang="en" href="/about/">` +
+		`<link rel="alternate" hreflang="x-default" href="/about/">`
+	for file, expected := range map[string]string{
+		"about/index.html":      `<html lang="en">` + aboutLinks + `Hello About Home missing|/fr/about/ |About </html>`,
+		"fr/about/index.html":   `<html lang="fr">` + frAboutLinks + `Bonjour À propos Home missing|/about/ |À propos </html>`,
+		"fr/contact/index.html": `<html lang="fr">Bonjour Contact Home missing||À propos </html>`,
+		"fr/blog/art/index.html": `<html lang="fr"><link rel="alternate" hreflang="fr" href="/fr/blog/art/">` +
+			`<link rel="alternate" hreflang="en" href="/blog/post/"><link rel="alternate" hreflang="x-default" href="/blog/post/">` +
+			`Bonjour Article Home missing|/blog/post/ |À propos </html>`,
+	} {
+		output, err := os.ReadFile(filepath.Join(config.OutputDir, filepath.FromSlash(file)))
+		if err != nil {
+			t.Fatalf("expected %s to be rendered, got %v", file, err)
+		}
+		if string(output) != expected {
+			t.Errorf("expected %s for %s, got %s", expected, file, output)
+		}
+	}
+
+	// each language has its own section tree
+	for _, page := range website.Pages {
+		if page.Section == nil || page.Section.Language != page.Language {
+			t.Errorf("expected %s in a section of its language, got %+v", page.InputPath, page.Section)
+		}
+	}
+	if len(website.Root.Sections) != 1 || website.Root.Sections[0].Name != "blog" {
+		t.Errorf("expected only the blog section in the default language, got %+v", website.Root.Sections)
+	}
+}
+
+func TestRenderLanguagesUglyURLs(t *testing.T) {
+	config := writeTestSite(t, map[string]string{
+		"pages/layouts/default.tmpl": `{{range .Page.Translations}}{{.URL}}{{end}}`,
+		"pages/blog/index.tmpl":      ``,
+		"pages/blog/index.fr.tmpl":   ``,
+		"pages/blog/post.tmpl":       ``,
+		"pages/blog/post.fr.tmpl":    ``,
+	})
+	config.Languages = []Language{{Code: "en", Name: "English"}, {Code: "fr", Name: "Français"}}
+	config.UglyURLs = true
+
+	website, err := Load(config)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := Render(website, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for file, expected := range map[string]string{
+		"blog/index.html":    `/fr/blog/`,
+		"fr/blog/index.html": `/blog/`,
+		"blog/post.html":     `/fr/blog/post.html`,
+		"fr/blog/post.html":  `/blog/post.html`,
+	} {
+		output, err := os.ReadFile(filepath.Join(config.OutputDir, filepath.FromSlash(file)))
+		if err != nil {
+			t.Fatalf("expected %s to be rendered, got %v", file, err)
+		}
+		if string(output) != expected {
+			t.Errorf("expected %s for %s, got %s", expected, file, output)
+		}
+	}
+}
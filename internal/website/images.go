@@ -0,0 +1,227 @@
+package website
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+	"image"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/eastcitysoftware/ditto/internal/images"
+)
+
+const (
+	DefaultCacheDir = "resources"
+	imagesCacheDir  = "images"
+	imageFunc       = "image"
+)
+
+// Image is an image from the static directories, or one derived from it, as
+// seen by templates. Derived images are written next to their source in the
+// output directory.
+type Image struct {
+	URL    string
+	Width  int
+	Height int
+
+	source    string
+	rel       string
+	processor *imageProcessor
+}
+
+// imageProcessor derives images for templates. Derived images are cached by
+// the hash of the source and the processing options, so a rebuild only
+// decodes images that changed.
+type imageProcessor struct {
+	config *WebsiteConfig
+	mu     sync.Mutex
+	hashes map[string]string
+}
+
+func newImageProcessor(config *WebsiteConfig) *imageProcessor {
+	return &imageProcessor{config: config, hashes: map[string]string{}}
+}
+
+// open finds an image in the static directories, the project first and then
+// the themes in order.
+func (p *imageProcessor) open(name string) (*Image, error) {
+	rel := strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(name)), "/")
+	dirs := getStaticDirs(p.config)
+	for i := len(dirs) - 1; i >= 0; i-- {
+		source := filepath.Join(dirs[i], filepath.FromSlash(rel))
+		file, err := os.Open(source)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to open image %s: %w", source, err)
+		}
+		defer file.Close()
+
+		imageConfig, _, err := image.DecodeConfig(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read image %s: %w", source, err)
+		}
+		return &Image{
+			URL:       "/" + rel,
+			Width:     imageConfig.Width,
+			Height:    imageConfig.Height,
+			source:    source,
+			rel:       rel,
+			processor: p}, nil
+	}
+	return nil, fmt.Errorf("image %s not found in static directories", name)
+}
+
+// Resize scales the image to a spec like "800x", "x600" or "800x600 png q80".
+func (i *Image) Resize(spec string) (*Image, error) {
+	return i.processor.process(i, images.Resize, spec)
+}
+
+// Fit scales the image down to fit within the size of the spec.
+func (i *Image) Fit(spec string) (*Image, error) {
+	return i.processor.process(i, images.Fit, spec)
+}
+
+// Fill scales and crops the image to cover the size of the spec.
+func (i *Image) Fill(spec string) (*Image, error) {
+	return i.processor.process(i, images.Fill, spec)
+}
+
+// Crop cuts the size of the spec from the centre of the image.
+func (i *Image) Crop(spec string) (*Image, error) {
+	return i.processor.process(i, images.Crop, spec)
+}
+
+// Srcset resizes the image to each width, widths larger than the image are
+// skipped, and returns them as a srcset attribute value.
+func (i *Image) Srcset(widths ...int) (template.Srcset, error) {
+	candidates := []string{}
+	for _, width := range widths {
+		if width > i.Width {
+			continue
+		}
+		resized, err := i.Resize(fmt.Sprintf("%dx", width))
+		if err != nil {
+			return "", err
+		}
+		candidates = append(candidates, fmt.Sprintf("%s %dw", resized.URL, resized.Width))
+	}
+	if len(candidates) == 0 {
+		candidates = append(candidates, fmt.Sprintf("%s %dw", i.URL, i.Width))
+	}
+	return template.Srcset(strings.Join(candidates, ", ")), nil
+}
+
+func (p *imageProcessor) process(source *Image, method string, spec string) (*Image, error) {
+	options, err := images.ParseOptions(spec)
+	if err != nil {
+		return nil, fmt.Errorf("image %s: %w", source.rel, err)
+	}
+
+	hash, err := p.sourceHash(source.source)
+	if err != nil {
+		return nil, err
+	}
+
+	// derived images keep the source name with the options and a hash
+	if options.Format == "" {
+		format, ok := images.FormatOf(source.rel)
+		if !ok {
+			return nil, fmt.Errorf("image %s: unsupported format", source.rel)
+		}
+		options.Format = format
+	}
+	key := sha256.Sum256([]byte(hash + method + options.String() + options.Format))
+	base := strings.TrimSuffix(path.Base(source.rel), path.Ext(source.rel))
+	name := fmt.Sprintf("%s_%s_%s_%s%s", base, method, options.String(), hex.EncodeToString(key[:])[:12], images.Extension(options.Format))
+	rel := path.Join(path.Dir(source.rel), name)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	cached := filepath.Join(p.config.CacheDir, imagesCacheDir, name)
+	if _, err := os.Stat(cached); err != nil {
+		img, err := decodeFile(source.source)
+		if err != nil {
+			return nil, fmt.Errorf("image %s: %w", source.rel, err)
+		}
+		processed, err := images.Process(img, method, options)
+		if err != nil {
+			return nil, fmt.Errorf("image %s: %w", source.rel, err)
+		}
+
+		var encoded bytes.Buffer
+		if err := images.Encode(&encoded, processed, options.Format, options.Quality); err != nil {
+			return nil, fmt.Errorf("image %s: %w", source.rel, err)
+		}
+		if err := os.MkdirAll(filepath.Dir(cached), os.ModePerm); err != nil {
+			return nil, fmt.Errorf("failed to create image cache %s: %w", filepath.Dir(cached), err)
+		}
+		if err := os.WriteFile(cached, encoded.Bytes(), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write image cache %s: %w", cached, err)
+		}
+	}
+
+	output := filepath.Join(p.config.OutputDir, filepath.FromSlash(rel))
+	if !outputExists(p.config, output) {
+		if err := copyOutput(p.config, cached, output); err != nil {
+			return nil, err
+		}
+	}
+
+	file, err := os.Open(cached)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open image cache %s: %w", cached, err)
+	}
+	defer file.Close()
+	imageConfig, _, err := image.DecodeConfig(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image cache %s: %w", cached, err)
+	}
+
+	// processing a derived image again starts from the derived image
+	return &Image{
+		URL:       "/" + rel,
+		Width:     imageConfig.Width,
+		Height:    imageConfig.Height,
+		source:    cached,
+		rel:       rel,
+		processor: p}, nil
+}
+
+// sourceHash returns the hash of a source image, hashes are kept for the
+// load so each source is read once
+func (p *imageProcessor) sourceHash(source string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if hash, ok := p.hashes[source]; ok {
+		return hash, nil
+	}
+
+	content, err := os.ReadFile(source)
+	if err != nil {
+		return "", fmt.Errorf("failed to read image %s: %w", source, err)
+	}
+	sum := sha256.Sum256(content)
+	p.hashes[source] = hex.EncodeToString(sum[:])
+	return p.hashes[source], nil
+}
+
+func decodeFile(source string) (image.Image, error) {
+	file, err := os.Open(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open image %s: %w", source, err)
+	}
+	defer file.Close()
+
+	img, _, err := images.Decode(file)
+	return img, err
+}
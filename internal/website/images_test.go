@@ -0,0 +1,90 @@
+package website
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderImages(t *testing.T) {
+	var photo bytes.Buffer
+	if err := png.Encode(&photo, image.NewRGBA(image.Rect(0, 0, 400, 200))); err != nil {
+		t.Fatal(err)
+	}
+
+	config := writeTestSite(t, map[string]string{
+		"pages/layouts/default.tmpl": `{{block "content" .}}{{end}}`,
+		"pages/index.tmpl": `{{define "content"}}{{$photo := image "photos/a.png"}}` +
+			`{{with $photo.Resize "100x jpg"}}<img src="{{.URL}}" width="{{.Width}}" height="{{.Height}}">{{end}}` +
+			`{{with ($photo.Fill "50x50").Resize "x10"}}<img src="{{.URL}}" width="{{.Width}}">{{end}}` +
+			`<img srcset="{{$photo.Srcset 100 200 800}}">{{end}}`,
+		"static/photos/a.png": photo.String(),
+	})
+
+	website, err := Load(config)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := Render(website, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	output, err := os.ReadFile(filepath.Join(config.OutputDir, "index.html"))
+	if err != nil {
+		t.Fatalf("expected page to be rendered, got %v", err)
+	}
+	for _, want := range []string{
+		`<img src="/photos/a_resize_100x0_q75_`,
+		`.jpg" width="100" height="50">`,
+		`_fill_50x50_`,
+		`_resize_0x10_`,
+		`.png" width="10">`,
+		`_resize_200x0_`,
+		`.png 200w">`,
+	} {
+		if !strings.Contains(string(output), want) {
+			t.Errorf("expected %q in %s", want, output)
+		}
+	}
+
+	// derived images are written to the output and the cache
+	derived, _ := filepath.Glob(filepath.Join(config.OutputDir, "photos", "a_*"))
+	cached, _ := filepath.Glob(filepath.Join(config.CacheDir, imagesCacheDir, "*"))
+	if len(derived) != 5 || len(cached) != 5 {
+		t.Fatalf("expected 5 derived and cached images, got %v and %v", derived, cached)
+	}
+
+	// a rebuild copies cached images without processing them again
+	for _, file := range derived {
+		os.Remove(file)
+	}
+	if err := os.WriteFile(cached[0], []byte("cached"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	website, err = Load(config)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := Render(website, ""); err == nil || !strings.Contains(err.Error(), "failed to read image cache") {
+		t.Errorf("expected the cached image to be used, got %v", err)
+	}
+}
+
+func TestImageNotFound(t *testing.T) {
+	config := writeTestSite(t, map[string]string{
+		"pages/layouts/default.tmpl": `{{block "content" .}}{{end}}`,
+		"pages/index.tmpl":           `{{define "content"}}{{(image "missing.png").URL}}{{end}}`,
+	})
+
+	website, err := Load(config)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := Render(website, ""); err == nil || !strings.Contains(err.Error(), "image missing.png not found") {
+		t.Errorf("expected image not found error, got %v", err)
+	}
+}
@@ -0,0 +1,244 @@
+package website
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	menuKey  = "menu"
+	titleKey = "title"
+)
+
+// MenuEntry is an entry of a named menu, declared in the config file or by a
+// page with the menu frontmatter key. Entries are nested by the children of
+// config entries or by naming the identifier of a parent entry.
+type MenuEntry struct {
+	Identifier string       `json:"identifier"`
+	Name       string       `json:"name"`
+	URL        string       `json:"url"`
+	Weight     float64      `json:"weight"`
+	Parent     string       `json:"parent"`
+	Children   []*MenuEntry `json:"children"`
+	Page       *Page        `json:"-"`
+}
+
+// MenuItem is a menu entry as seen from the page being rendered. Active is
+// set for the entry of the page itself and ActiveTrail for the entries above
+// it.
+type MenuItem struct {
+	Identifier  string
+	Name        string
+	URL         string
+	Weight      float64
+	Page        *Page
+	Active      bool
+	ActiveTrail bool
+	Children    []*MenuItem
+}
+
+// getMenus resolves the menus of the config file and the page frontmatter
+// into trees ordered by weight and then name.
+func getMenus(pages []Page, config *WebsiteConfig) (map[string][]*MenuEntry, error) {
+	entries := map[string][]*MenuEntry{}
+	for name, configEntries := range config.Menus {
+		for _, entry := range configEntries {
+			entries[name] = append(entries[name], flattenMenuEntry(entry, "")...)
+		}
+	}
+
+	for i := range pages {
+		page := &pages[i]
+		pageEntries, err := getPageMenuEntries(page)
+		if err != nil {
+			return nil, fmt.Errorf("invalid frontmatter in page %s: %w", page.InputPath, err)
+		}
+		for name, entry := range pageEntries {
+			entries[name] = append(entries[name], entry)
+		}
+	}
+
+	menus := map[string][]*MenuEntry{}
+	for name, menuEntries := range entries {
+		tree, err := buildMenuTree(menuEntries)
+		if err != nil {
+			return nil, fmt.Errorf("invalid menu %s: %w", name, err)
+		}
+		menus[name] = tree
+	}
+	return menus, nil
+}
+
+// flattenMenuEntry copies a config entry and its children, so that loading
+// the website again starts from the config as it was read
+func flattenMenuEntry(entry *MenuEntry, parent string) []*MenuEntry {
+	flat := *entry
+	flat.Children = nil
+	if flat.Identifier == "" {
+		flat.Identifier = flat.Name
+	}
+	if parent != "" {
+		flat.Parent = parent
+	}
+
+	entries := []*MenuEntry{&flat}
+	for _, child := range entry.Children {
+		entries = append(entries, flattenMenuEntry(child, flat.Identifier)...)
+	}
+	return entries
+}
+
+// getPageMenuEntries reads the menu frontmatter of a page. It is the name of
+// a menu, a list of names, or an object of menu names to entries that set
+// the name, weight, parent or identifier of the page entry.
+func getPageMenuEntries(page *Page) (map[string]*MenuEntry, error) {
+	value, ok := page.Data[menuKey]
+	if !ok {
+		return nil, nil
+	}
+
+	newEntry := func() *MenuEntry {
+		entry := &MenuEntry{Identifier: page.URL, Name: page.URL, URL: page.URL, Page: page}
+		if title, ok := page.Data[titleKey].(string); ok {
+			entry.Name = title
+		}
+		if weight, ok := page.Data[weightKey].(float64); ok {
+			entry.Weight = weight
+		}
+		return entry
+	}
+
+	entries := map[string]*MenuEntry{}
+	switch value := value.(type) {
+	case string:
+		entries[value] = newEntry()
+	case []any:
+		for _, name := range value {
+			name, ok := name.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s must list menu names", menuKey)
+			}
+			entries[name] = newEntry()
+		}
+	case map[string]any:
+		for name, options := range value {
+			options, ok := options.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("%s.%s must be an object", menuKey, name)
+			}
+			entry := newEntry()
+			for key, option := range options {
+				var err error
+				switch key {
+				case "identifier":
+					entry.Identifier, err = menuString(name, key, option)
+				case "name":
+					entry.Name, err = menuString(name, key, option)
+				case "parent":
+					entry.Parent, err = menuString(name, key, option)
+				case "weight":
+					weight, ok := option.(float64)
+					if !ok {
+						err = fmt.Errorf("%s.%s.weight must be a number", menuKey, name)
+					}
+					entry.Weight = weight
+				default:
+					err = fmt.Errorf("unknown option %s.%s.%s", menuKey, name, key)
+				}
+				if err != nil {
+					return nil, err
+				}
+			}
+			entries[name] = entry
+		}
+	default:
+		return nil, fmt.Errorf("%s must be a menu name, a list or an object", menuKey)
+	}
+	return entries, nil
+}
+
+func menuString(menu string, key string, value any) (string, error) {
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("%s.%s.%s must be a string", menuKey, menu, key)
+	}
+	return s, nil
+}
+
+func buildMenuTree(entries []*MenuEntry) ([]*MenuEntry, error) {
+	byIdentifier := map[string]*MenuEntry{}
+	for _, entry := range entries {
+		if _, ok := byIdentifier[entry.Identifier]; ok {
+			return nil, fmt.Errorf("duplicate entry %q", entry.Identifier)
+		}
+		byIdentifier[entry.Identifier] = entry
+	}
+
+	tree := []*MenuEntry{}
+	for _, entry := range entries {
+		if entry.Parent == "" {
+			tree = append(tree, entry)
+			continue
+		}
+		parent, ok := byIdentifier[entry.Parent]
+		if !ok {
+			return nil, fmt.Errorf("entry %q has unknown parent %q", entry.Identifier, entry.Parent)
+		}
+		parent.Children = append(parent.Children, entry)
+	}
+
+	// entries can only be reached from the top of the menu if there is no
+	// cycle of parents
+	reached := 0
+	var sortEntries func(entries []*MenuEntry)
+	sortEntries = func(entries []*MenuEntry) {
+		sort.SliceStable(entries, func(i, j int) bool {
+			if entries[i].Weight != entries[j].Weight {
+				return entries[i].Weight < entries[j].Weight
+			}
+			return strings.ToLower(entries[i].Name) < strings.ToLower(entries[j].Name)
+		})
+		for _, entry := range entries {
+			reached++
+			sortEntries(entry.Children)
+		}
+	}
+	sortEntries(tree)
+	if reached != len(entries) {
+		return nil, fmt.Errorf("entries have cyclic parents")
+	}
+	return tree, nil
+}
+
+// getMenuItems returns the menus as seen from the page, entries for pages in
+// other languages are left out
+func getMenuItems(menus map[string][]*MenuEntry, page *Page) map[string][]*MenuItem {
+	items := map[string][]*MenuItem{}
+	for name, entries := range menus {
+		items[name], _ = getMenuItemTree(entries, page)
+	}
+	return items
+}
+
+func getMenuItemTree(entries []*MenuEntry, page *Page) ([]*MenuItem, bool) {
+	items := []*MenuItem{}
+	anyActive := false
+	for _, entry := range entries {
+		if entry.Page != nil && entry.Page.Language != page.Language {
+			continue
+		}
+		item := &MenuItem{
+			Identifier: entry.Identifier,
+			Name:       entry.Name,
+			URL:        entry.URL,
+			Weight:     entry.Weight,
+			Page:       entry.Page,
+			Active:     entry.URL == page.URL,
+		}
+		item.Children, item.ActiveTrail = getMenuItemTree(entry.Children, page)
+		anyActive = anyActive || item.Active || item.ActiveTrail
+		items = append(items, item)
+	}
+	return items, anyActive
+}
@@ -0,0 +1,75 @@
+package website
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderMenus(t *testing.T) {
+	config := writeTestSite(t, map[string]string{
+		"pages/layouts/default.tmpl": `{{define "items"}}{{range .}}[{{.Name}}{{if .Active}}*{{end}}{{if .ActiveTrail}}+{{end}}{{template "items" .Children}}]{{end}}{{end}}` +
+			`{{template "items" .Page.Menus.main}}|{{template "items" .Page.Menus.footer}}`,
+		"pages/index.tmpl":      `{{/* {"title": "Home", "menu": "main", "weight": 1} */}}`,
+		"pages/docs/setup.tmpl": `{{/* {"title": "Setup", "menu": {"main": {"parent": "Docs", "weight": 2}}} */}}`,
+		"pages/docs/usage.tmpl": `{{/* {"title": "Usage", "menu": {"main": {"parent": "Docs", "weight": 1}}} */}}`,
+		"pages/about.tmpl":      `{{/* {"title": "About", "menu": ["main", "footer"], "weight": 9} */}}`,
+	})
+	config.Menus = map[string][]*MenuEntry{
+		"main": {{Name: "Docs", URL: "/docs/", Weight: 5, Children: []*MenuEntry{
+			{Name: "Reference", URL: "https://example.com/ref", Weight: 3},
+		}}},
+		"footer": {{Name: "Source", URL: "https://example.com"}},
+	}
+
+	website, err := Load(config)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := Render(website, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for file, expected := range map[string]string{
+		"index.html":            `[Home*][Docs[Usage][Setup][Reference]][About]|[Source][About]`,
+		"docs/usage/index.html": `[Home][Docs+[Usage*][Setup][Reference]][About]|[Source][About]`,
+		"about/index.html":      `[Home][Docs[Usage][Setup][Reference]][About*]|[Source][About*]`,
+	} {
+		output, err := os.ReadFile(filepath.Join(config.OutputDir, filepath.FromSlash(file)))
+		if err != nil {
+			t.Fatalf("expected %s to be rendered, got %v", file, err)
+		}
+		if string(output) != expected {
+			t.Errorf("expected %s for %s, got %s", expected, file, output)
+		}
+	}
+
+	// the config is left as it was so the website can be loaded again
+	if len(config.Menus["main"][0].Children) != 1 {
+		t.Errorf("expected config menus to be unchanged, got %+v", config.Menus["main"][0].Children)
+	}
+}
+
+func TestLoadMenuErrors(t *testing.T) {
+	for name, test := range map[string]struct {
+		frontmatter string
+		expected    string
+	}{
+		"unknown parent": {`{"menu": {"main": {"parent": "missing"}}}`, `entry "/page/" has unknown parent "missing"`},
+		"invalid value":  {`{"menu": 1}`, "menu must be a menu name, a list or an object"},
+		"unknown option": {`{"menu": {"main": {"url": "/x/"}}}`, "unknown option menu.main.url"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			config := writeTestSite(t, map[string]string{
+				"pages/layouts/default.tmpl": `{{block "content" .}}{{end}}`,
+				"pages/page.tmpl":            `{{/* ` + test.frontmatter + ` */}}`,
+			})
+
+			_, err := Load(config)
+			if err == nil || !strings.Contains(err.Error(), test.expected) {
+				t.Errorf("expected error containing %q, got %v", test.expected, err)
+			}
+		})
+	}
+}
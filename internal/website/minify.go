@@ -0,0 +1,56 @@
+package website
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/eastcitysoftware/ditto/internal/minify"
+)
+
+const minifyKey = "minify"
+
+// shouldMinifyPage reports whether a rendered page is minified, pages can
+// opt out with minify set to false in their frontmatter
+func shouldMinifyPage(page *Page, config *WebsiteConfig) bool {
+	if !config.Minify || !minify.IsSupported(page.Format) {
+		return false
+	}
+	enabled, ok := page.Data[minifyKey].(bool)
+	return !ok || enabled
+}
+
+// shouldMinifyStatic reports whether a static file is minified. Files that
+// are already minified, like app.min.js, and files matching an exclude
+// pattern are copied as they are.
+func shouldMinifyStatic(rel string, config *WebsiteConfig) bool {
+	rel = filepath.ToSlash(rel)
+	if !config.Minify || !minify.IsSupported(path.Ext(rel)) || strings.Contains(path.Base(rel), ".min.") {
+		return false
+	}
+	for _, pattern := range config.MinifyExclude {
+		if matched, _ := path.Match(pattern, rel); matched {
+			return false
+		}
+		if matched, _ := path.Match(pattern, path.Base(rel)); matched {
+			return false
+		}
+	}
+	return true
+}
+
+func minifyFile(src string, dst string, config *WebsiteConfig) error {
+	content, err := os.ReadFile(src)
+	if err != nil {
+		return fmt.Errorf("failed to read static file %s: %w", src, err)
+	}
+
+	minified, err := minify.Minify(filepath.Ext(src), string(content))
+	if err != nil {
+		return fmt.Errorf("failed to minify static file %s: %w", src, err)
+	}
+
+	return writeOutput(config, dst, []byte(minified))
+}
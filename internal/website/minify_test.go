@@ -0,0 +1,44 @@
+package website
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRenderMinified(t *testing.T) {
+	config := writeTestSite(t, map[string]string{
+		"pages/layouts/default.tmpl": "<html>\n  <body>\n    {{block \"content\" .}}{{end}}\n  </body>\n</html>\n",
+		"pages/index.tmpl":           "{{define \"content\"}}<p>\n  Home\n</p>{{end}}",
+		"pages/raw.tmpl":             "{{/* {\"minify\": false} */}}\n{{define \"content\"}}<p>\n  Raw\n</p>{{end}}",
+		"static/site.css":            "a {\n  color: red;\n}\n",
+		"static/app.min.js":          "let a = 1;\n\n",
+		"static/vendor/lib.js":       "let b = 2;\n\n",
+	})
+	config.Minify = true
+	config.MinifyExclude = []string{"vendor/*"}
+
+	website, err := Load(config)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := Render(website, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for file, expected := range map[string]string{
+		"index.html":     "<html> <body> <p> Home </p> </body> </html>",
+		"raw/index.html": "<html>\n  <body>\n    <p>\n  Raw\n</p>\n  </body>\n</html>\n",
+		"site.css":       "a{color:red}",
+		"app.min.js":     "let a = 1;\n\n",
+		"vendor/lib.js":  "let b = 2;\n\n",
+	} {
+		output, err := os.ReadFile(filepath.Join(config.OutputDir, filepath.FromSlash(file)))
+		if err != nil {
+			t.Fatalf("expected %s to be written, got %v", file, err)
+		}
+		if string(output) != expected {
+			t.Errorf("expected %q for %s, got %q", expected, file, output)
+		}
+	}
+}
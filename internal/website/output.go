@@ -0,0 +1,184 @@
+package website
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// outputsFile lists the files written by the last build, it is kept in the
+// cache directory so it is not deployed with the output
+const outputsFile = "outputs.json"
+
+// outputSet records the files written to the output directory, so the next
+// full render removes every one of them, including the outputs of pages that
+// were since removed, renamed or switched to ugly urls
+type outputSet struct {
+	mu    sync.Mutex
+	names map[string]bool
+}
+
+func (s *outputSet) add(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.names == nil {
+		s.names = map[string]bool{}
+	}
+	s.names[name] = true
+}
+
+func (s *outputSet) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.names = map[string]bool{}
+}
+
+func (s *outputSet) list() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, 0, len(s.names))
+	for name := range s.names {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// writeOutput writes a file in the output directory, or to memory when the
+// website is served from memory
+func writeOutput(config *WebsiteConfig, file string, content []byte) error {
+	if config.Memory != nil {
+		name, err := outputName(config, file)
+		if err != nil {
+			return err
+		}
+		config.Memory.WriteFile(name, content)
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create output directory %s: %w", file, err)
+	}
+	if err := os.WriteFile(file, content, 0644); err != nil {
+		return fmt.Errorf("failed to create output file %s: %w", file, err)
+	}
+	if name, err := outputName(config, file); err == nil {
+		config.outputs.add(name)
+	}
+	return nil
+}
+
+// copyOutput copies a file from the project into the output
+func copyOutput(config *WebsiteConfig, src string, dst string) error {
+	content, err := os.ReadFile(src)
+	if err != nil {
+		return fmt.Errorf("failed to open static file %s: %w", src, err)
+	}
+	return writeOutput(config, dst, content)
+}
+
+func outputExists(config *WebsiteConfig, file string) bool {
+	if config.Memory != nil {
+		name, err := outputName(config, file)
+		return err == nil && config.Memory.Exists(name)
+	}
+	_, err := os.Stat(file)
+	return err == nil
+}
+
+// cleanOutput removes the outputs of the previous build before a full
+// render, files in memory are all removed. Without a list of outputs, from a
+// build before outputs were recorded, the rendered pages are removed.
+func cleanOutput(config *WebsiteConfig) error {
+	config.outputs.reset()
+	if config.Memory != nil {
+		config.Memory.Clear()
+		return nil
+	}
+
+	names, err := readOutputs(config)
+	if err != nil {
+		return err
+	}
+	if names == nil {
+		return removeFileRecursive(config.OutputDir, "index.html")
+	}
+
+	for _, name := range names {
+		file := filepath.Join(config.OutputDir, filepath.FromSlash(name))
+		if rel, err := filepath.Rel(config.OutputDir, file); err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove output file %s: %w", file, err)
+		}
+		removeEmptyDirs(filepath.Dir(file), config.OutputDir)
+	}
+	return nil
+}
+
+// removeEmptyDirs removes dir and its parents up to root while they are
+// empty, the output directory itself is kept
+func removeEmptyDirs(dir string, root string) {
+	for dir != root && strings.HasPrefix(dir, root) {
+		if os.Remove(dir) != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+func readOutputs(config *WebsiteConfig) ([]string, error) {
+	if config.CacheDir == "" {
+		return nil, nil
+	}
+
+	file := filepath.Join(config.CacheDir, outputsFile)
+	content, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read output list %s: %w", file, err)
+	}
+
+	names := []string{}
+	if err := json.Unmarshal(content, &names); err != nil {
+		return nil, fmt.Errorf("failed to parse output list %s: %w", file, err)
+	}
+	return names, nil
+}
+
+// saveOutputs writes the list of outputs for the next full render to clean
+func saveOutputs(config *WebsiteConfig) error {
+	if config.Memory != nil || config.CacheDir == "" {
+		return nil
+	}
+
+	content, err := json.Marshal(config.outputs.list())
+	if err != nil {
+		return fmt.Errorf("failed to encode output list: %w", err)
+	}
+	if err := os.MkdirAll(config.CacheDir, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create cache directory %s: %w", config.CacheDir, err)
+	}
+	file := filepath.Join(config.CacheDir, outputsFile)
+	if err := os.WriteFile(file, content, 0644); err != nil {
+		return fmt.Errorf("failed to write output list %s: %w", file, err)
+	}
+	return nil
+}
+
+// outputName is the slash separated path of an output file
+func outputName(config *WebsiteConfig, file string) (string, error) {
+	rel, err := filepath.Rel(config.OutputDir, file)
+	if err != nil {
+		return "", fmt.Errorf("failed to determine output name for %s: %w", file, err)
+	}
+	return filepath.ToSlash(rel), nil
+}
@@ -0,0 +1,131 @@
+package website
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eastcitysoftware/ditto/internal/memfs"
+)
+
+func TestRenderToMemory(t *testing.T) {
+	config := writeTestSite(t, map[string]string{
+		"pages/layouts/default.tmpl": `<html><body>{{block "content" .}}{{end}}</body></html>`,
+		"pages/index.tmpl":           `{{define "content"}}home{{end}}`,
+		"pages/about.tmpl":           `{{/* {"aliases": ["/old-about/"]} */}}{{define "content"}}about{{end}}`,
+		"static/site.css":            "a {}",
+	})
+	config.Memory = memfs.New()
+	config.RedirectsFile = true
+
+	// files from an earlier render are cleared
+	config.Memory.WriteFile("stale.html", []byte("stale"))
+
+	website, err := Load(config)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := Render(website, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := "_redirects,about/index.html,index.html,old-about/index.html,site.css"
+	if names := strings.Join(config.Memory.Names(), ","); names != expected {
+		t.Errorf("expected %s in memory, got %s", expected, names)
+	}
+	if content, _ := config.Memory.ReadFile("about/index.html"); string(content) != "<html><body>about</body></html>" {
+		t.Errorf("unexpected page %q", content)
+	}
+
+	entries, err := os.ReadDir(config.OutputDir)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected the output directory to be untouched, got %v", entries)
+	}
+
+	// a single page render replaces the page in memory
+	if err := os.WriteFile(website.Pages[0].InputPath, []byte(`{{define "content"}}changed{{end}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Render(website, website.Pages[0].InputPath); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	content, _ := config.Memory.ReadFile(strings.TrimPrefix(website.Pages[0].URL, "/") + "index.html")
+	if !strings.Contains(string(content), "changed") {
+		t.Errorf("expected the page to be re-rendered in memory, got %q", content)
+	}
+}
+
+func TestRenderToMemoryReplacesFiles(t *testing.T) {
+	config := writeTestSite(t, map[string]string{
+		"pages/layouts/default.tmpl": `{{block "content" .}}{{end}}`,
+		"pages/index.tmpl":           `{{define "content"}}home{{end}}`,
+	})
+	live := memfs.New()
+	config.Memory = live
+
+	website, err := Load(config)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := Render(website, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// a failed rebuild keeps serving the last good files
+	if err := os.WriteFile(website.Pages[0].InputPath, []byte(`{{define "content"}}{{template "missing"}}{{end}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Render(website, ""); err == nil {
+		t.Fatal("expected the render to fail")
+	}
+	if config.Memory != live {
+		t.Fatal("expected the served file system to be kept")
+	}
+	if content, _ := live.ReadFile("index.html"); string(content) != "home" {
+		t.Errorf("expected the last good page, got %q", content)
+	}
+}
+
+func TestCleanOutputRemovesPreviousOutputs(t *testing.T) {
+	config := writeTestSite(t, map[string]string{
+		"pages/layouts/default.tmpl": `{{block "content" .}}{{end}}`,
+		"pages/index.tmpl":           `home`,
+		"pages/about.tmpl":           `about`,
+		"pages/docs/setup.tmpl":      `setup`,
+		"pages/feed.xml.tmpl":        `<feed/>`,
+		"public/CNAME":               "example.com",
+		"public/docs/notes.txt":      "kept",
+	})
+	config.UglyURLs = true
+
+	for _, removed := range []string{"", "about.tmpl", "docs/setup.tmpl", "feed.xml.tmpl"} {
+		if removed != "" {
+			if err := os.Remove(filepath.Join(config.PagesDir, filepath.FromSlash(removed))); err != nil {
+				t.Fatal(err)
+			}
+		}
+		website, err := Load(config)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if err := Render(website, ""); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	for _, file := range []string{"about.html", "docs/setup.html", "feed.xml"} {
+		if _, err := os.Stat(filepath.Join(config.OutputDir, filepath.FromSlash(file))); !os.IsNotExist(err) {
+			t.Errorf("expected stale %s to be removed, got %v", file, err)
+		}
+	}
+	// files that were not written by a build are left alone
+	for _, file := range []string{"index.html", "CNAME", "docs/notes.txt"} {
+		if _, err := os.Stat(filepath.Join(config.OutputDir, filepath.FromSlash(file))); err != nil {
+			t.Errorf("expected %s to be kept, got %v", file, err)
+		}
+	}
+}
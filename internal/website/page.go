@@ -0,0 +1,94 @@
+package website
+
+import (
+	"html/template"
+	"maps"
+	"sort"
+
+	"github.com/eastcitysoftware/ditto/internal/render"
+)
+
+// PageKey is the key of the page object in the data passed to templates, it
+// is set by ditto and replaces any frontmatter value with the same key.
+const PageKey = "Page"
+
+const summaryKey = "summary"
+
+// PageContext is the page object available to templates as .Page. The
+// content fields are computed from the rendered content block, so they are
+// only set for the layout and not inside the content block itself.
+type PageContext struct {
+	Name   string
+	URL    string
+	Status string
+
+	// navigation within the section tree, pages are listed in the order of
+	// their section
+	Section   *Section
+	Parent    *Page
+	Ancestors []*Page
+	Children  []*Page
+	Siblings  []*Page
+	Prev      *Page
+	Next      *Page
+
+	// every html page of the site in all languages, ordered by url, for
+	// feeds and sitemaps
+	AllPages []*Page
+
+	// menus by name, with the entries of the page marked active
+	Menus map[string][]*MenuItem
+
+	// the page in other languages, with alternate links for the head
+	Language     Language
+	Translations []*Page
+	Hreflang     template.HTML
+
+	Headings        []*render.Heading
+	TableOfContents template.HTML
+	WordCount       int
+	ReadingTime     int
+	Summary         string
+}
+
+// getTemplateData returns a copy of the page data with the page object set
+func getTemplateData(website *Website, page *Page) (map[string]any, *PageContext) {
+	context := &PageContext{Name: page.Name, URL: page.URL, Status: page.Status}
+	context.setNavigation(page)
+	context.AllPages = website.allPages
+	context.Menus = getMenuItems(website.Menus, page)
+	context.Language, _ = website.Config.getLanguage(page.Language)
+	context.Translations = getTranslations(website.Pages, page)
+	context.Hreflang = hreflangLinks(page, context.Translations, website.Config)
+	data := maps.Clone(page.Data)
+	if data == nil {
+		data = map[string]any{}
+	}
+	data[PageKey] = context
+	return data, context
+}
+
+// getAllPages lists the html pages ordered by url
+func getAllPages(pages []Page) []*Page {
+	allPages := []*Page{}
+	for i := range pages {
+		if pages[i].Format == HTMLFormat {
+			allPages = append(allPages, &pages[i])
+		}
+	}
+	sort.Slice(allPages, func(i, j int) bool { return allPages[i].URL < allPages[j].URL })
+	return allPages
+}
+
+// setContentInfo describes the rendered content on the page object, a
+// summary in the frontmatter is used over the generated one.
+func (c *PageContext) setContentInfo(info render.ContentInfo, pageData map[string]any) {
+	c.Headings = info.Headings
+	c.TableOfContents = render.TableOfContents(info.Headings)
+	c.WordCount = info.WordCount
+	c.ReadingTime = info.ReadingTime
+	c.Summary = info.Summary
+	if summary, ok := pageData[summaryKey].(string); ok {
+		c.Summary = summary
+	}
+}
@@ -0,0 +1,74 @@
+package website
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRenderPageContext(t *testing.T) {
+	config := writeTestSite(t, map[string]string{
+		"pages/layouts/default.tmpl": `{{.Page.TableOfContents}}{{block "content" .}}{{end}}<p>{{.Page.WordCount}} words, {{.Page.ReadingTime}} min, {{.Page.URL}}</p><meta content="{{.Page.Summary}}">`,
+		"pages/docs.tmpl": `{{/* {"title": "Docs"} */}}
+{{define "content"}}<h2>{{.title}} intro</h2><p>Read this</p>{{end}}`,
+		"pages/about.tmpl": `{{/* {"summary": "About us"} */}}
+{{define "content"}}<p>Hello</p>{{end}}`,
+	})
+
+	website, err := Load(config)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := Render(website, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for file, expected := range map[string]string{
+		"docs/index.html": `<nav class="toc"><ul><li><a href="#docs-intro">Docs intro</a></li></ul></nav>` +
+			`<h2 id="docs-intro">Docs intro</h2><p>Read this</p><p>4 words, 1 min, /docs/</p><meta content="Docs intro Read this">`,
+		"about/index.html": `<p>Hello</p><p>1 words, 1 min, /about/</p><meta content="About us">`,
+	} {
+		output, err := os.ReadFile(filepath.Join(config.OutputDir, file))
+		if err != nil {
+			t.Fatalf("expected %s to be rendered, got %v", file, err)
+		}
+		if string(output) != expected {
+			t.Errorf("expected %s, got %s", expected, output)
+		}
+	}
+}
+
+func TestRenderFeedAndSitemap(t *testing.T) {
+	config := writeTestSite(t, map[string]string{
+		"pages/layouts/default.tmpl": `{{block "content" .}}{{end}}`,
+		"pages/partials/entry.tmpl":  `<entry><title>{{.Data.title}}</title><link>{{.URL}}</link></entry>`,
+		"pages/index.tmpl":           `{{/* {"title": "Home"} */}}`,
+		"pages/blog/index.tmpl":      `{{/* {"title": "Blog"} */}}`,
+		"pages/blog/one.tmpl":        `{{/* {"title": "One & Two", "date": "2025-01-01"} */}}`,
+		"pages/blog/two.tmpl":        `{{/* {"title": "Newer", "date": "2025-02-01"} */}}`,
+		"pages/blog/feed.xml.tmpl":   `<feed>{{range .Page.Section.Pages}}{{template "entry" .}}{{end}}</feed>`,
+		"pages/sitemap.xml.tmpl":     `<urlset>{{range .Page.AllPages}}<url>{{.URL}}</url>{{end}}</urlset>`,
+	})
+
+	website, err := Load(config)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := Render(website, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for file, expected := range map[string]string{
+		"blog/feed.xml": `<feed><entry><title>Newer</title><link>/blog/two/</link></entry>` +
+			`<entry><title>One &amp; Two</title><link>/blog/one/</link></entry></feed>`,
+		"sitemap.xml": `<urlset><url>/</url><url>/blog/</url><url>/blog/one/</url><url>/blog/two/</url></urlset>`,
+	} {
+		output, err := os.ReadFile(filepath.Join(config.OutputDir, filepath.FromSlash(file)))
+		if err != nil {
+			t.Fatalf("expected %s to be rendered, got %v", file, err)
+		}
+		if string(output) != expected {
+			t.Errorf("expected %s for %s, got %s", expected, file, output)
+		}
+	}
+}
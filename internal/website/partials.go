@@ -0,0 +1,147 @@
+package website
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const DefaultPartialsDir = "partials"
+
+// partialsCache holds the partials found in each directory so that scoped
+// partials are collected once per load
+type partialsCache map[string]map[string]string
+
+// getSharedPartials collects the partials visible to every page from the
+// project partials directory and then each theme, the first file found for a
+// name wins.
+func getSharedPartials(config *WebsiteConfig, cache partialsCache) (map[string]string, error) {
+	sources := []string{filepath.Join(config.PagesDir, DefaultPartialsDir)}
+	for _, theme := range config.Themes {
+		sources = append(sources, filepath.Join(theme, DefaultPartialsDir))
+	}
+
+	partials := map[string]string{}
+	for _, source := range sources {
+		sourcePartials, err := cache.read(source)
+		if err != nil {
+			return nil, err
+		}
+		for name, file := range sourcePartials {
+			if _, exists := partials[name]; !exists {
+				partials[name] = file
+			}
+		}
+	}
+	return partials, nil
+}
+
+// getScopedPartials collects the partials in partials directories between
+// the pages directory and the page, nearer partials override outer ones.
+func getScopedPartials(pageFile string, pagesDir string, cache partialsCache) (map[string]string, error) {
+	rel, err := filepath.Rel(pagesDir, filepath.Dir(pageFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine partials for %s: %w", pageFile, err)
+	}
+	if rel == "." {
+		return nil, nil
+	}
+
+	partials := map[string]string{}
+	dir := pagesDir
+	for _, part := range strings.Split(filepath.ToSlash(rel), "/") {
+		dir = filepath.Join(dir, part)
+		dirPartials, err := cache.read(filepath.Join(dir, DefaultPartialsDir))
+		if err != nil {
+			return nil, err
+		}
+		for name, file := range dirPartials {
+			partials[name] = file
+		}
+	}
+	return partials, nil
+}
+
+// read returns the partials in dir keyed by their path relative to dir
+// without the template extension, so partials/nav/main.tmpl is "nav/main".
+func (c partialsCache) read(dir string) (map[string]string, error) {
+	if partials, ok := c[dir]; ok {
+		return partials, nil
+	}
+
+	partials := map[string]string{}
+	if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+		c[dir] = partials
+		return partials, nil
+	}
+
+	files, err := getFilesRecursive(dir, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return nil, err
+		}
+		name := strings.TrimSuffix(filepath.ToSlash(rel), TmplExtension)
+		partials[name] = file
+	}
+	c[dir] = partials
+	return partials, nil
+}
+
+// isPartialFile reports whether a file under the pages directory is inside a
+// partials directory rather than being a page.
+func isPartialFile(file string, pagesDir string) bool {
+	rel, err := filepath.Rel(pagesDir, filepath.Dir(file))
+	if err != nil || rel == "." {
+		return false
+	}
+	for _, part := range strings.Split(filepath.ToSlash(rel), "/") {
+		if part == DefaultPartialsDir {
+			return true
+		}
+	}
+	return false
+}
+
+func addPartials(t *template.Template, partials map[string]string) error {
+	// add in a stable order so redefinitions are predictable
+	names := make([]string, 0, len(partials))
+	for name := range partials {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		content, err := os.ReadFile(partials[name])
+		if err != nil {
+			return fmt.Errorf("failed to read partial %s: %w", partials[name], err)
+		}
+		if _, err := t.New(name).Parse(string(content)); err != nil {
+			return fmt.Errorf("failed to parse partial %s: %w", partials[name], err)
+		}
+	}
+	return nil
+}
+
+// readPartials reads partial files by name for templates that are not built
+// from a layout, later maps override earlier ones
+func readPartials(partialMaps ...map[string]string) (map[string]string, error) {
+	contents := map[string]string{}
+	for _, partials := range partialMaps {
+		for name, partialFile := range partials {
+			content, err := os.ReadFile(partialFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read partial %s: %w", partialFile, err)
+			}
+			contents[name] = string(content)
+		}
+	}
+	return contents, nil
+}
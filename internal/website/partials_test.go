@@ -0,0 +1,67 @@
+package website
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadPartials(t *testing.T) {
+	config := writeTestSite(t, map[string]string{
+		"pages/layouts/default.tmpl":        `{{template "nav/main" .}}|{{block "content" .}}{{end}}`,
+		"pages/partials/nav/main.tmpl":      `main nav`,
+		"pages/partials/card.tmpl":          `shared card`,
+		"pages/index.tmpl":                  `{{define "content"}}{{template "card" .}}{{end}}`,
+		"pages/blog/partials/card.tmpl":     `blog card`,
+		"pages/blog/post.tmpl":              `{{define "content"}}{{template "card" .}}{{end}}`,
+		"pages/blog/2025/partials/tag.tmpl": `tag`,
+		"pages/blog/2025/post.tmpl":         `{{define "content"}}{{template "card" .}} {{template "tag" .}}{{end}}`,
+	})
+
+	website, err := Load(config)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(website.Pages) != 3 {
+		t.Fatalf("expected partials not to be loaded as pages, got %d pages", len(website.Pages))
+	}
+	if website.Partials["nav/main"] == "" || website.Partials["card"] == "" {
+		t.Errorf("expected shared partials to be named by path, got %v", website.Partials)
+	}
+
+	if err := Render(website, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := map[string]string{
+		"index.html":                "main nav|shared card",
+		"blog/post/index.html":      "main nav|blog card",
+		"blog/2025/post/index.html": "main nav|blog card tag",
+	}
+	for name, content := range expected {
+		output, err := os.ReadFile(filepath.Join(config.OutputDir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatalf("expected %s to be rendered, got %v", name, err)
+		}
+		if string(output) != content {
+			t.Errorf("expected %s to contain %q, got %q", name, content, output)
+		}
+	}
+}
+
+func TestIsPartialFile(t *testing.T) {
+	tests := map[string]bool{
+		"pages/partials/nav.tmpl":        true,
+		"pages/blog/partials/card.tmpl":  true,
+		"pages/blog/partials/a/b.tmpl":   true,
+		"pages/partials.tmpl":            false,
+		"pages/blog/post.tmpl":           false,
+		"pages/blog/partials-guide.tmpl": false,
+	}
+
+	for file, expected := range tests {
+		if isPartialFile(file, "pages") != expected {
+			t.Errorf("expected isPartialFile(%s) to be %v", file, expected)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package website
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	slugKey = "slug"
+	urlKey  = "url"
+	dateKey = "date"
+)
+
+var (
+	permalinkTokenPattern = regexp.MustCompile(`:[a-z]+`)
+	slugInvalidPattern    = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// resolvePageName determines the output name of a page, honouring a url
+// override, then the permalink pattern of its section, then a slug override
+// of the default name derived from the file path.
+func resolvePageName(pageFile string, pageData map[string]any, config *WebsiteConfig) (string, error) {
+	if url, ok := pageData[urlKey].(string); ok && url != "" {
+		return getPermalinkName(url)
+	}
+
+	rel, err := filepath.Rel(config.PagesDir, pageFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to determine page name for %s: %w", pageFile, err)
+	}
+	rel = filepath.ToSlash(rel)
+
+	// section permalinks only apply to html pages, the index page of a
+	// section keeps the url of the section
+	section := getSection(rel)
+	isFormatPage := isFormatName(strings.TrimSuffix(rel, path.Ext(rel)))
+	isIndexPage := path.Base(rel) == indexPage
+	if pattern, ok := config.Permalinks[section]; ok && section != "" && !isFormatPage && !isIndexPage {
+		permalink, err := expandPermalink(pattern, rel, pageData)
+		if err != nil {
+			return "", fmt.Errorf("failed to expand permalink for page %s: %w", pageFile, err)
+		}
+		return getPermalinkName(permalink)
+	}
+
+	pageName, err := getPageName(pageFile, config.PagesDir)
+	if err != nil {
+		return "", err
+	}
+
+	if slug, ok := pageData[slugKey].(string); ok && slug != "" {
+		dir := path.Dir(rel)
+		if isFormatPage {
+			return getPermalinkName(path.Join("/", dir, slug+path.Ext(pageName)))
+		}
+		return getPermalinkName(path.Join("/", dir, slug) + "/")
+	}
+	return pageName, nil
+}
+
+func expandPermalink(pattern string, rel string, pageData map[string]any) (string, error) {
+	var expandErr error
+	permalink := permalinkTokenPattern.ReplaceAllStringFunc(pattern, func(token string) string {
+		value, err := getPermalinkToken(token[1:], rel, pageData)
+		if err != nil && expandErr == nil {
+			expandErr = err
+		}
+		return value
+	})
+	return permalink, expandErr
+}
+
+func getPermalinkToken(token string, rel string, pageData map[string]any) (string, error) {
+	filename := strings.TrimSuffix(path.Base(rel), path.Ext(rel))
+
+	switch token {
+	case "section":
+		return getSection(rel), nil
+	case "filename":
+		return filename, nil
+	case "slug":
+		if slug, ok := pageData[slugKey].(string); ok && slug != "" {
+			return slug, nil
+		}
+		return filename, nil
+	case "title":
+		if title, ok := pageData["title"].(string); ok && title != "" {
+			return slugify(title), nil
+		}
+		return filename, nil
+	case "year", "month", "day":
+		date, err := getDate(pageData, dateKey)
+		if err != nil {
+			return "", err
+		}
+		if date.IsZero() {
+			return "", fmt.Errorf("permalink token :%s requires a %s in the frontmatter", token, dateKey)
+		}
+		switch token {
+		case "year":
+			return date.Format("2006"), nil
+		case "month":
+			return date.Format("01"), nil
+		default:
+			return date.Format("02"), nil
+		}
+	}
+	return "", fmt.Errorf("unknown permalink token :%s", token)
+}
+
+// getSection returns the top level directory of a page relative to the pages
+// directory, or an empty string for pages at the root.
+func getSection(rel string) string {
+	section, _, found := strings.Cut(rel, "/")
+	if !found {
+		return ""
+	}
+	return section
+}
+
+func slugify(value string) string {
+	slug := slugInvalidPattern.ReplaceAllString(strings.ToLower(value), "-")
+	return strings.Trim(slug, "-")
+}
+
+func checkDuplicateOutputs(pages []Page) error {
+	outputs := map[string]string{}
+	for _, page := range pages {
+		if existing, ok := outputs[page.OutputPath]; ok {
+			return fmt.Errorf("pages %s and %s both render to %s", existing, page.InputPath, page.OutputPath)
+		}
+		outputs[page.OutputPath] = page.InputPath
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package website
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolvePageName(t *testing.T) {
+	config := &WebsiteConfig{
+		PagesDir:   "pages",
+		Permalinks: map[string]string{"blog": "/:section/:year/:month/:slug/"},
+	}
+
+	tests := []struct {
+		file     string
+		data     map[string]any
+		expected string
+	}{
+		{"pages/about.tmpl", nil, "about/index.html"},
+		{"pages/about.tmpl", map[string]any{"slug": "about-us"}, "about-us/index.html"},
+		{"pages/docs/intro.tmpl", map[string]any{"slug": "start"}, "docs/start/index.html"},
+		{"pages/docs/intro.tmpl", map[string]any{"url": "/getting-started/"}, "getting-started/index.html"},
+		{"pages/blog/post.tmpl", map[string]any{"date": "2025-03-09"}, "blog/2025/03/post/index.html"},
+		{"pages/blog/post.tmpl", map[string]any{"date": "2025-03-09", "slug": "hello"}, "blog/2025/03/hello/index.html"},
+		{"pages/blog/post.tmpl", map[string]any{"url": "/hello.html"}, "hello.html"},
+		{"pages/blog/index.tmpl", nil, "blog/index.html"},
+	}
+
+	for _, test := range tests {
+		pageName, err := resolvePageName(test.file, test.data, config)
+		if err != nil {
+			t.Fatalf("expected no error for %s, got %v", test.file, err)
+		}
+		if pageName != test.expected {
+			t.Errorf("expected %s for %s %v, got %s", test.expected, test.file, test.data, pageName)
+		}
+	}
+}
+
+func TestResolvePageNameMissingDate(t *testing.T) {
+	config := &WebsiteConfig{
+		PagesDir:   "pages",
+		Permalinks: map[string]string{"blog": "/blog/:year/:slug/"},
+	}
+
+	_, err := resolvePageName("pages/blog/post.tmpl", map[string]any{}, config)
+	if err == nil || !strings.Contains(err.Error(), "date") {
+		t.Fatalf("expected missing date error, got %v", err)
+	}
+}
+
+func TestExpandPermalinkTitle(t *testing.T) {
+	permalink, err := expandPermalink("/:section/:title/", "news/post.tmpl", map[string]any{"title": "Hello, World!"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if permalink != "/news/hello-world/" {
+		t.Errorf("expected /news/hello-world/, got %s", permalink)
+	}
+}
+
+func TestLoadDuplicateOutputs(t *testing.T) {
+	config := writeTestSite(t, map[string]string{
+		"pages/layouts/default.tmpl": `{{block "content" .}}{{end}}`,
+		"pages/about.tmpl":           ``,
+		"pages/company.tmpl":         `{{/* {"slug": "about"} */}}`,
+	})
+
+	_, err := Load(config)
+	if err == nil || !strings.Contains(err.Error(), filepath.Join("about", "index.html")) {
+		t.Fatalf("expected duplicate output error, got %v", err)
+	}
+}
+
+func TestLoadUglyURLsAndFormats(t *testing.T) {
+	config := writeTestSite(t, map[string]string{
+		"pages/layouts/default.tmpl": `{{block "content" .}}{{end}}`,
+		"pages/index.tmpl":           ``,
+		"pages/about.tmpl":           ``,
+		"pages/blog/index.tmpl":      ``,
+		"pages/feed.xml.tmpl":        `<title>{{.title}}</title>`,
+		"pages/robots.txt.tmpl":      `User-agent: *`,
+	})
+	config.UglyURLs = true
+
+	website, err := Load(config)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := map[string]string{
+		"about.tmpl":      "/about.html",
+		"index.tmpl":      "/",
+		"index.tmpl-blog": "/blog/",
+		"feed.xml.tmpl":   "/feed.xml",
+		"robots.txt.tmpl": "/robots.txt",
+	}
+	for _, page := range website.Pages {
+		key := filepath.Base(page.InputPath)
+		if filepath.Base(filepath.Dir(page.InputPath)) == "blog" {
+			key += "-blog"
+		}
+		if page.URL != expected[key] {
+			t.Errorf("expected url %s for %s, got %s", expected[key], page.InputPath, page.URL)
+		}
+	}
+
+	if err := Render(website, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package website
+
+import (
+	"fmt"
+	"html"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	aliasesKey    = "aliases"
+	RedirectsFile = "_redirects"
+)
+
+type Redirect struct {
+	From       string
+	To         string
+	InputPath  string
+	OutputPath string
+}
+
+// getRedirects collects the aliases declared by pages, each alias is written
+// as a redirect page so it must not collide with a page or another alias.
+func getRedirects(pages []Page, config *WebsiteConfig) ([]Redirect, error) {
+	outputs := map[string]string{}
+	for _, page := range pages {
+		outputs[page.OutputPath] = page.InputPath
+	}
+
+	redirects := []Redirect{}
+	for _, page := range pages {
+		aliases, err := getAliases(page.Data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid frontmatter in page %s: %w", page.InputPath, err)
+		}
+
+		for _, alias := range aliases {
+			aliasName, err := getPermalinkName(alias)
+			if err != nil {
+				return nil, fmt.Errorf("invalid alias in page %s: %w", page.InputPath, err)
+			}
+			if config.UglyURLs {
+				aliasName = getUglyPageName(aliasName)
+			}
+
+			outputPath := filepath.Join(config.OutputDir, filepath.FromSlash(aliasName))
+			if existing, ok := outputs[outputPath]; ok {
+				return nil, fmt.Errorf("alias %s of page %s collides with %s", alias, page.InputPath, existing)
+			}
+			outputs[outputPath] = page.InputPath
+
+			redirects = append(redirects, Redirect{
+				From:       path.Clean("/" + alias),
+				To:         page.URL,
+				InputPath:  page.InputPath,
+				OutputPath: outputPath})
+		}
+	}
+	return redirects, nil
+}
+
+func getAliases(pageData map[string]any) ([]string, error) {
+	value, ok := pageData[aliasesKey]
+	if !ok {
+		return nil, nil
+	}
+
+	values, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%s must be a list of urls", aliasesKey)
+	}
+
+	aliases := []string{}
+	for _, value := range values {
+		alias, ok := value.(string)
+		if !ok || alias == "" {
+			return nil, fmt.Errorf("%s must be a list of urls", aliasesKey)
+		}
+		aliases = append(aliases, alias)
+	}
+	return aliases, nil
+}
+
+func renderRedirect(redirect Redirect, config *WebsiteConfig) error {
+	url := html.EscapeString(redirect.To)
+	content := fmt.Sprintf(`<!DOCTYPE html><html><head><title>%s</title>`+
+		`<link rel="canonical" href="%s"><meta name="robots" content="noindex">`+
+		`<meta http-equiv="refresh" content="0; url=%s"></head></html>`, url, url, url)
+
+	return writeOutput(config, redirect.OutputPath, []byte(content))
+}
+
+func renderRedirectsFile(redirects []Redirect, config *WebsiteConfig) error {
+	var sb strings.Builder
+	for _, redirect := range redirects {
+		fmt.Fprintf(&sb, "%s %s 301\n", redirect.From, redirect.To)
+	}
+
+	return writeOutput(config, filepath.Join(config.OutputDir, RedirectsFile), []byte(sb.String()))
+}
@@ -0,0 +1,57 @@
+package website
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderAliases(t *testing.T) {
+	config := writeTestSite(t, map[string]string{
+		"pages/layouts/default.tmpl": `{{block "content" .}}{{end}}`,
+		"pages/docs/start.tmpl":      `{{/* {"aliases": ["/getting-started/", "/old/start.html"]} */}}`,
+	})
+	config.RedirectsFile = true
+
+	website, err := Load(config)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(website.Redirects) != 2 {
+		t.Fatalf("expected 2 redirects, got %d", len(website.Redirects))
+	}
+	if err := Render(website, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	aliasPage, err := os.ReadFile(filepath.Join(config.OutputDir, "getting-started", "index.html"))
+	if err != nil {
+		t.Fatalf("expected alias page to be rendered, got %v", err)
+	}
+	if !strings.Contains(string(aliasPage), `content="0; url=/docs/start/"`) {
+		t.Errorf("expected meta refresh to /docs/start/, got %s", aliasPage)
+	}
+
+	redirects, err := os.ReadFile(filepath.Join(config.OutputDir, RedirectsFile))
+	if err != nil {
+		t.Fatalf("expected redirects file to be rendered, got %v", err)
+	}
+	expected := "/getting-started /docs/start/ 301\n/old/start.html /docs/start/ 301\n"
+	if string(redirects) != expected {
+		t.Errorf("expected redirects %q, got %q", expected, redirects)
+	}
+}
+
+func TestLoadAliasCollision(t *testing.T) {
+	config := writeTestSite(t, map[string]string{
+		"pages/layouts/default.tmpl": `{{block "content" .}}{{end}}`,
+		"pages/about.tmpl":           ``,
+		"pages/company.tmpl":         `{{/* {"aliases": ["/about/"]} */}}`,
+	})
+
+	_, err := Load(config)
+	if err == nil || !strings.Contains(err.Error(), "collides") {
+		t.Fatalf("expected alias collision error, got %v", err)
+	}
+}
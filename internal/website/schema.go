@@ -0,0 +1,204 @@
+package website
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"reflect"
+	"sort"
+)
+
+// fallbackSchema applies to pages whose section has no schema of its own,
+// including pages at the root of the pages directory
+const fallbackSchema = "*"
+
+// builtinKeys are frontmatter keys understood by ditto itself, they are
+// always allowed by strict schemas
+var builtinKeys = map[string]bool{
+	dataKey:        true,
+	permalinkKey:   true,
+	slugKey:        true,
+	urlKey:         true,
+	dateKey:        true,
+	draftKey:       true,
+	publishDateKey: true,
+	expiryDateKey:  true,
+	aliasesKey:     true,
+	layoutKey:      true,
+	summaryKey:     true,
+	searchKey:      true,
+	weightKey:      true,
+	menuKey:        true,
+	minifyKey:      true,
+
+	translationKeyKey: true,
+}
+
+var fieldTypes = map[string]bool{
+	"string":  true,
+	"number":  true,
+	"integer": true,
+	"boolean": true,
+	"array":   true,
+	"object":  true,
+	"date":    true,
+}
+
+type FieldSchema struct {
+	Type     string `json:"type"`
+	Required bool   `json:"required"`
+	Enum     []any  `json:"enum"`
+	Default  any    `json:"default"`
+}
+
+type SectionSchema struct {
+	// strict schemas reject fields they do not declare
+	Strict bool                   `json:"strict"`
+	Fields map[string]FieldSchema `json:"fields"`
+}
+
+func checkSchemas(schemas map[string]SectionSchema) error {
+	for section, schema := range schemas {
+		for name, field := range schema.Fields {
+			if field.Type != "" && !fieldTypes[field.Type] {
+				return fmt.Errorf("schema for section %s: field %q has unknown type %q", section, name, field.Type)
+			}
+			if field.Default != nil {
+				if err := checkField(field, field.Default); err != nil {
+					return fmt.Errorf("schema for section %s: default for field %q %w", section, name, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// validateFrontmatter checks page data against the schema for its section and
+// fills in defaults for missing fields.
+func validateFrontmatter(pageFile string, pageData map[string]any, config *WebsiteConfig) error {
+	if len(config.Schemas) == 0 {
+		return nil
+	}
+
+	// translations use the schema of the page without its language
+	_, namingFile := getPageLanguage(pageFile, config)
+	rel, err := filepath.Rel(config.PagesDir, namingFile)
+	if err != nil {
+		return fmt.Errorf("failed to determine section for %s: %w", pageFile, err)
+	}
+
+	section := getSection(filepath.ToSlash(rel))
+	schema, ok := config.Schemas[section]
+	if !ok || section == "" {
+		section = fallbackSchema
+		if schema, ok = config.Schemas[fallbackSchema]; !ok {
+			return nil
+		}
+	}
+
+	// sort field names so errors are reported in a stable order
+	names := make([]string, 0, len(schema.Fields))
+	for name := range schema.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	errs := []error{}
+	for _, name := range names {
+		field := schema.Fields[name]
+		value, ok := pageData[name]
+		if !ok && field.Default != nil {
+			pageData[name] = copyValue(field.Default)
+			continue
+		}
+		if !ok {
+			if field.Required {
+				errs = append(errs, fmt.Errorf("page %s: field %q is required by the %s schema", pageFile, name, section))
+			}
+			continue
+		}
+		if err := checkField(field, value); err != nil {
+			errs = append(errs, fmt.Errorf("page %s: field %q %w", pageFile, name, err))
+		}
+	}
+
+	if schema.Strict {
+		keys := make([]string, 0, len(pageData))
+		for key := range pageData {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if _, ok := schema.Fields[key]; !ok && !builtinKeys[key] {
+				errs = append(errs, fmt.Errorf("page %s: field %q is not in the %s schema", pageFile, key, section))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func checkField(field FieldSchema, value any) error {
+	if field.Type != "" && !isFieldType(field.Type, value) {
+		return fmt.Errorf("must be of type %s, got %v", field.Type, value)
+	}
+
+	if len(field.Enum) > 0 {
+		for _, allowed := range field.Enum {
+			if reflect.DeepEqual(allowed, value) {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of %v, got %v", field.Enum, value)
+	}
+	return nil
+}
+
+func isFieldType(fieldType string, value any) bool {
+	switch fieldType {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		number, ok := value.(float64)
+		return ok && number == math.Trunc(number)
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "date":
+		date, err := getDate(map[string]any{"date": value}, "date")
+		return err == nil && !date.IsZero()
+	}
+	return false
+}
+
+// copyValue copies a json value, so pages given a default list or object do
+// not share it
+func copyValue(value any) any {
+	switch value := value.(type) {
+	case []any:
+		copied := make([]any, len(value))
+		for i, item := range value {
+			copied[i] = copyValue(item)
+		}
+		return copied
+	case map[string]any:
+		copied := make(map[string]any, len(value))
+		for key, item := range value {
+			copied[key] = copyValue(item)
+		}
+		return copied
+	}
+	return value
+}
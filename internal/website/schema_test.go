@@ -0,0 +1,199 @@
+package website
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateFrontmatter(t *testing.T) {
+	config := &WebsiteConfig{
+		PagesDir: "pages",
+		Schemas: map[string]SectionSchema{
+			"blog": {
+				Strict: true,
+				Fields: map[string]FieldSchema{
+					"title":  {Type: "string", Required: true},
+					"tags":   {Type: "array"},
+					"status": {Type: "string", Enum: []any{"open", "closed"}},
+					"author": {Type: "string", Default: "Staff"},
+					"weight": {Type: "integer"},
+				},
+			},
+		},
+	}
+
+	pageData := map[string]any{"title": "Post", "status": "open", "weight": 2.0, "date": "2025-01-01"}
+	if err := validateFrontmatter("pages/blog/post.tmpl", pageData, config); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if pageData["author"] != "Staff" {
+		t.Errorf("expected default author to be applied, got %v", pageData["author"])
+	}
+
+	// pages outside the section are not validated
+	if err := validateFrontmatter("pages/about.tmpl", map[string]any{"titel": 1.0}, config); err != nil {
+		t.Fatalf("expected no error outside of section, got %v", err)
+	}
+
+	pageData = map[string]any{"titel": "Post", "tags": "go", "status": "pending", "weight": 1.5}
+	err := validateFrontmatter("pages/blog/post.tmpl", pageData, config)
+	if err == nil {
+		t.Fatal("expected validation errors, got nil")
+	}
+
+	expected := []string{
+		`page pages/blog/post.tmpl: field "status" must be one of [open closed], got pending`,
+		`page pages/blog/post.tmpl: field "tags" must be of type array, got go`,
+		`page pages/blog/post.tmpl: field "title" is required by the blog schema`,
+		`page pages/blog/post.tmpl: field "weight" must be of type integer, got 1.5`,
+		`page pages/blog/post.tmpl: field "titel" is not in the blog schema`,
+	}
+	if err.Error() != strings.Join(expected, "\n") {
+		t.Errorf("expected errors:\n%s\ngot:\n%s", strings.Join(expected, "\n"), err)
+	}
+}
+
+func TestValidateFrontmatterCopiesDefaults(t *testing.T) {
+	config := &WebsiteConfig{
+		PagesDir: "pages",
+		Schemas: map[string]SectionSchema{
+			"blog": {Fields: map[string]FieldSchema{
+				"tags":   {Type: "array", Default: []any{"news"}},
+				"social": {Type: "object", Default: map[string]any{"links": []any{"rss"}}},
+			}},
+		},
+	}
+
+	first, second := map[string]any{}, map[string]any{}
+	for _, pageData := range []map[string]any{first, second} {
+		if err := validateFrontmatter("pages/blog/post.tmpl", pageData, config); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	// changing the data of one page leaves the other page and the schema alone
+	first["tags"].([]any)[0] = "changed"
+	first["social"].(map[string]any)["links"].([]any)[0] = "changed"
+	if second["tags"].([]any)[0] != "news" || second["social"].(map[string]any)["links"].([]any)[0] != "rss" {
+		t.Errorf("expected pages not to share default values, got %v", second)
+	}
+}
+
+func TestValidateFrontmatterFallback(t *testing.T) {
+	config := &WebsiteConfig{
+		PagesDir: "pages",
+		Schemas: map[string]SectionSchema{
+			"*": {Fields: map[string]FieldSchema{"title": {Required: true}}},
+		},
+	}
+
+	err := validateFrontmatter("pages/index.tmpl", map[string]any{}, config)
+	if err == nil || !strings.Contains(err.Error(), `"title" is required by the * schema`) {
+		t.Fatalf("expected required title error, got %v", err)
+	}
+}
+
+func TestCheckSchemas(t *testing.T) {
+	err := checkSchemas(map[string]SectionSchema{
+		"blog": {Fields: map[string]FieldSchema{"title": {Type: "text"}}},
+	})
+	if err == nil || !strings.Contains(err.Error(), `unknown type "text"`) {
+		t.Fatalf("expected unknown type error, got %v", err)
+	}
+
+	err = checkSchemas(map[string]SectionSchema{
+		"blog": {Fields: map[string]FieldSchema{"count": {Type: "number", Default: "one"}}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "default") {
+		t.Fatalf("expected invalid default error, got %v", err)
+	}
+}
+
+func TestLoadReportsSchemaErrors(t *testing.T) {
+	config := writeTestSite(t, map[string]string{
+		"pages/layouts/default.tmpl": `{{block "content" .}}{{end}}`,
+		"pages/blog/one.tmpl":        `{{/* {"titel": "One"} */}}`,
+		"pages/blog/two.tmpl":        `{{/* {} */}}`,
+	})
+	config.Schemas = map[string]SectionSchema{
+		"blog": {Fields: map[string]FieldSchema{"title": {Type: "string", Required: true}}},
+	}
+
+	_, err := Load(config)
+	if err == nil || !strings.Contains(err.Error(), "one.tmpl") || !strings.Contains(err.Error(), "two.tmpl") {
+		t.Fatalf("expected errors naming both pages, got %v", err)
+	}
+}
+
+func TestLoadValidatesTranslatedPages(t *testing.T) {
+	config := writeTestSite(t, map[string]string{
+		"pages/layouts/default.tmpl": `{{block "content" .}}{{end}}`,
+		"pages/blog/post.tmpl":       `{{/* {"title": "Post"} */}}`,
+		"pages/fr/blog/article.tmpl": `{{/* {} */}}`,
+	})
+	config.Languages = []Language{{Code: "en", Name: "English"}, {Code: "fr", Name: "Français"}}
+	config.Schemas = map[string]SectionSchema{
+		"blog": {Fields: map[string]FieldSchema{"title": {Type: "string", Required: true}}},
+	}
+
+	_, err := Load(config)
+	if err == nil || !strings.Contains(err.Error(), filepath.Join("fr", "blog", "article.tmpl")) || !strings.Contains(err.Error(), "title") {
+		t.Fatalf("expected the blog schema to apply to the translated page, got %v", err)
+	}
+}
+
+func TestLoadAppliesDefaultsToTranslatedPages(t *testing.T) {
+	config := writeTestSite(t, map[string]string{
+		"pages/layouts/default.tmpl": `{{block "content" .}}{{end}}`,
+		"pages/blog/_defaults.json":  `{"author": "Staff"}`,
+		"pages/blog/post.tmpl":       `{{/* {} */}}`,
+		"pages/fr/blog/post.tmpl":    `{{/* {} */}}`,
+	})
+	config.Languages = []Language{{Code: "en", Name: "English"}, {Code: "fr", Name: "Français"}}
+	config.Schemas = map[string]SectionSchema{
+		"blog": {Fields: map[string]FieldSchema{"author": {Type: "string", Required: true}}},
+	}
+
+	website, err := Load(config)
+	if err != nil {
+		t.Fatalf("expected the blog defaults to apply to the translated page, got %v", err)
+	}
+	for _, page := range website.Pages {
+		if page.Data["author"] != "Staff" {
+			t.Errorf("expected default author for %s, got %v", page.InputPath, page.Data["author"])
+		}
+	}
+}
+
+func TestLoadValidatesDataPages(t *testing.T) {
+	config := writeTestSite(t, map[string]string{
+		"pages/layouts/default.tmpl": `{{block "content" .}}{{end}}`,
+		"pages/products/item.tmpl":   `{{/* {"data": "products.json", "permalink": "/products/{{.slug}}/"} */}}`,
+		"data/products.json":         `[{"slug": "chair", "price": 10}, {"slug": "desk", "price": "cheap"}]`,
+	})
+	config.Schemas = map[string]SectionSchema{
+		"products": {Fields: map[string]FieldSchema{
+			"price": {Type: "number", Required: true},
+			"stock": {Type: "string", Default: "in stock"},
+		}},
+	}
+
+	_, err := Load(config)
+	if err == nil || !strings.Contains(err.Error(), "/products/desk/") || !strings.Contains(err.Error(), "price") {
+		t.Fatalf("expected a schema error for the desk item, got %v", err)
+	}
+
+	dataFile := filepath.Join(config.DataDir, "products.json")
+	if err := os.WriteFile(dataFile, []byte(`[{"slug": "chair", "price": 10}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	website, err := Load(config)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(website.Pages) != 1 || website.Pages[0].Data["stock"] != "in stock" {
+		t.Errorf("expected the schema default on the data page, got %+v", website.Pages)
+	}
+}
@@ -0,0 +1,203 @@
+package website
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/eastcitysoftware/ditto/internal/render"
+)
+
+const (
+	DefaultSearchOutput = "search.json"
+	searchKey           = "search"
+	tagsKey             = "tags"
+)
+
+// searchFields are the fields an index entry can have, the default fields are
+// used when the config names none
+var (
+	searchFields        = []string{"title", "url", "headings", "content", "summary", "tags", "language"}
+	defaultSearchFields = []string{"title", "url", "headings", "content", "tags"}
+)
+
+// SearchConfig enables a json search index of the html pages. Pages are
+// left out with search set to false in their frontmatter or by matching an
+// exclude pattern against their url.
+type SearchConfig struct {
+	Output  string   `json:"output"`
+	Fields  []string `json:"fields"`
+	Exclude []string `json:"exclude"`
+
+	// content is cut to a number of characters per page, and the build
+	// fails if the index grows over a number of bytes, 0 means no limit
+	MaxContentLength int `json:"maxContentLength"`
+	MaxSize          int `json:"maxSize"`
+}
+
+// searchIndex collects an entry for each page as it is rendered
+type searchIndex struct {
+	config  *SearchConfig
+	fields  []string
+	entries []map[string]any
+}
+
+func newSearchIndex(config *SearchConfig) (*searchIndex, error) {
+	if config == nil {
+		return nil, nil
+	}
+
+	fields := config.Fields
+	if len(fields) == 0 {
+		fields = defaultSearchFields
+	}
+	for _, field := range fields {
+		known := false
+		for _, searchField := range searchFields {
+			known = known || field == searchField
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown search field %q, use one of %s", field, strings.Join(searchFields, ", "))
+		}
+	}
+	for _, pattern := range config.Exclude {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid search exclude pattern %q: %w", pattern, err)
+		}
+	}
+	return &searchIndex{config: config, fields: fields}, nil
+}
+
+// isSearchable reports whether a page belongs in the index, unpublished
+// pages are never indexed
+func (s *searchIndex) isSearchable(page *Page) bool {
+	if page.Format != HTMLFormat || page.Status != "" {
+		return false
+	}
+	if enabled, ok := page.Data[searchKey].(bool); ok && !enabled {
+		return false
+	}
+	// trailing slashes are ignored, so /drafts/* matches the page /drafts/a/
+	for _, pattern := range s.config.Exclude {
+		if matched, _ := path.Match(trimTrailingSlash(pattern), trimTrailingSlash(page.URL)); matched {
+			return false
+		}
+	}
+	return true
+}
+
+func trimTrailingSlash(urlPath string) string {
+	if len(urlPath) > 1 {
+		return strings.TrimSuffix(urlPath, "/")
+	}
+	return urlPath
+}
+
+// add indexes a page from its rendered content
+func (s *searchIndex) add(page *Page, content string, info render.ContentInfo) {
+	if s == nil || !s.isSearchable(page) {
+		return
+	}
+
+	entry := map[string]any{}
+	for _, field := range s.fields {
+		switch field {
+		case "title":
+			title, _ := page.Data[titleKey].(string)
+			entry[field] = title
+		case "url":
+			entry[field] = page.URL
+		case "headings":
+			entry[field] = flattenHeadings(info.Headings, []string{})
+		case "content":
+			// pages without a content block have no content to index
+			if content == "" {
+				continue
+			}
+			entry[field] = truncateText(render.PlainText(content), s.config.MaxContentLength)
+		case "summary":
+			summary, ok := page.Data[summaryKey].(string)
+			if !ok {
+				summary = info.Summary
+			}
+			entry[field] = summary
+		case "tags":
+			entry[field] = getTags(page.Data)
+		case "language":
+			entry[field] = page.Language
+		}
+	}
+	s.entries = append(s.entries, entry)
+}
+
+// reset clears the entries before the pages are rendered again
+func (s *searchIndex) reset() {
+	if s != nil {
+		s.entries = nil
+	}
+}
+
+// write saves the index to the output directory
+func (s *searchIndex) write(config *WebsiteConfig) error {
+	if s == nil {
+		return nil
+	}
+
+	entries := s.entries
+	if entries == nil {
+		entries = []map[string]any{}
+	}
+	content, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("failed to encode search index: %w", err)
+	}
+	if s.config.MaxSize > 0 && len(content) > s.config.MaxSize {
+		return fmt.Errorf("search index is %d bytes, over the limit of %d, exclude pages or lower maxContentLength", len(content), s.config.MaxSize)
+	}
+
+	output := s.config.Output
+	if output == "" {
+		output = DefaultSearchOutput
+	}
+	dst := filepath.Join(config.OutputDir, filepath.FromSlash(strings.TrimPrefix(path.Clean("/"+output), "/")))
+	return writeOutput(config, dst, content)
+}
+
+func flattenHeadings(headings []*render.Heading, titles []string) []string {
+	for _, heading := range headings {
+		titles = append(titles, heading.Title)
+		titles = flattenHeadings(heading.Children, titles)
+	}
+	return titles
+}
+
+// getTags reads tags from the frontmatter as a list or a single string
+func getTags(pageData map[string]any) []string {
+	tags := []string{}
+	switch value := pageData[tagsKey].(type) {
+	case string:
+		tags = append(tags, value)
+	case []any:
+		for _, tag := range value {
+			if tag, ok := tag.(string); ok {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
+
+// truncateText cuts text to a number of characters at a word boundary
+func truncateText(text string, limit int) string {
+	runes := []rune(text)
+	if limit <= 0 || len(runes) <= limit {
+		return text
+	}
+	cut := string(runes[:limit])
+	if i := strings.LastIndexByte(cut, ' '); i > 0 {
+		cut = cut[:i]
+	}
+	return cut
+}
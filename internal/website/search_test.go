@@ -0,0 +1,122 @@
+package website
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRenderSearchIndex(t *testing.T) {
+	config := writeTestSite(t, map[string]string{
+		"pages/layouts/default.tmpl": `<nav>Menu</nav>{{block "content" .}}{{end}}`,
+		"pages/index.tmpl":           `{{/* {"title": "Home", "tags": ["intro", "start"]} */}}{{define "content"}}<h1>Welcome</h1><p>Hello &amp; welcome.</p><h2>More</h2>{{end}}`,
+		"pages/guide.tmpl":           `{{/* {"title": "Guide", "tags": "docs"} */}}{{define "content"}}<p>one two three four</p>{{end}}`,
+		"pages/hidden.tmpl":          `{{/* {"title": "Hidden", "search": false} */}}{{define "content"}}<p>secret</p>{{end}}`,
+		"pages/tags/go.tmpl":         `{{/* {"title": "Go"} */}}{{define "content"}}<p>tag page</p>{{end}}`,
+		"pages/drafts/a.tmpl":        `{{/* {"title": "Draft"} */}}{{define "content"}}<p>draft</p>{{end}}`,
+		"pages/plain.tmpl":           `{{/* {"title": "Plain"} */}}`,
+		"pages/feed.xml.tmpl":        `<feed></feed>`,
+	})
+	config.Search = &SearchConfig{Exclude: []string{"/tags/*/", "/drafts/*"}, MaxContentLength: 9}
+
+	website, err := Load(config)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := Render(website, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(config.OutputDir, DefaultSearchOutput))
+	if err != nil {
+		t.Fatalf("expected the search index to be written, got %v", err)
+	}
+	var entries []map[string]any
+	if err := json.Unmarshal(content, &entries); err != nil {
+		t.Fatalf("expected a json index, got %v", err)
+	}
+
+	byURL := map[string]map[string]any{}
+	for _, entry := range entries {
+		byURL[entry["url"].(string)] = entry
+	}
+	if len(byURL) != 3 || byURL["/"] == nil || byURL["/guide/"] == nil || byURL["/plain/"] == nil {
+		t.Fatalf("expected the home, guide and plain pages to be indexed, got %s", content)
+	}
+	if _, ok := byURL["/plain/"]["content"]; ok {
+		t.Errorf("expected no content for a page without a content block, got %v", byURL["/plain/"])
+	}
+
+	expected := map[string]any{
+		"title":    "Home",
+		"url":      "/",
+		"headings": []any{"Welcome", "More"},
+		"content":  "Welcome",
+		"tags":     []any{"intro", "start"},
+	}
+	if !reflect.DeepEqual(byURL["/"], expected) {
+		t.Errorf("expected %v, got %v", expected, byURL["/"])
+	}
+	if !reflect.DeepEqual(byURL["/guide/"]["tags"], []any{"docs"}) || byURL["/guide/"]["content"] != "one two" {
+		t.Errorf("unexpected guide entry %v", byURL["/guide/"])
+	}
+}
+
+func TestSearchIndexFieldsAndLimits(t *testing.T) {
+	config := writeTestSite(t, map[string]string{
+		"pages/layouts/default.tmpl": `{{block "content" .}}{{end}}`,
+		"pages/index.tmpl":           `{{/* {"title": "Home", "summary": "Short"} */}}{{define "content"}}<p>` + strings.Repeat("word ", 50) + `</p>{{end}}`,
+	})
+	config.Search = &SearchConfig{Output: "/data/index.json", Fields: []string{"url", "summary"}}
+
+	website, err := Load(config)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := Render(website, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(config.OutputDir, "data", "index.json"))
+	if err != nil {
+		t.Fatalf("expected the search index to be written, got %v", err)
+	}
+	if string(content) != `[{"summary":"Short","url":"/"}]` {
+		t.Errorf("unexpected index %s", content)
+	}
+
+	config.Search.Fields = nil
+	config.Search.MaxSize = 100
+	website, err = Load(config)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := Render(website, ""); err == nil || !strings.Contains(err.Error(), "over the limit") {
+		t.Errorf("expected an error for an index over the size limit, got %v", err)
+	}
+
+	config.Search = &SearchConfig{Fields: []string{"body"}}
+	if _, err := Load(config); err == nil || !strings.Contains(err.Error(), "unknown search field") {
+		t.Errorf("expected an error for an unknown field, got %v", err)
+	}
+}
+
+func TestTruncateText(t *testing.T) {
+	for _, test := range []struct {
+		text     string
+		limit    int
+		expected string
+	}{
+		{"hello world", 0, "hello world"},
+		{"hello world", 20, "hello world"},
+		{"hello world", 8, "hello"},
+		{"héllo wörld", 9, "héllo"},
+		{"helloworld", 5, "hello"},
+	} {
+		if got := truncateText(test.text, test.limit); got != test.expected {
+			t.Errorf("expected %q for %q cut to %d, got %q", test.expected, test.text, test.limit, got)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package website
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	weightKey = "weight"
+	indexPage = "index" + TmplExtension
+)
+
+// Section is a directory under the pages directory. Its pages are the pages
+// in the directory and the index pages of its sub sections, ordered by
+// frontmatter weight and then by date, newest first.
+type Section struct {
+	Name     string
+	Language string
+	Index    *Page
+	Pages    []*Page
+	Sections []*Section
+	Parent   *Section
+}
+
+// getSections builds the section tree of the html pages, setting the section
+// of every page. The index.tmpl page of a directory is the index of its
+// section, other pages belong to the section of their directory. On
+// multilingual sites each language has its own tree, named without the
+// language, and the tree of the default language is returned.
+func getSections(pages []Page, config *WebsiteConfig) (*Section, error) {
+	type sectionKey struct{ language, name string }
+	sections := map[sectionKey]*Section{}
+
+	var getOrCreate func(language string, name string) *Section
+	getOrCreate = func(language string, name string) *Section {
+		if section, ok := sections[sectionKey{language, name}]; ok {
+			return section
+		}
+		section := &Section{Name: name, Language: language}
+		if name != "" {
+			parentName := ""
+			if index := strings.LastIndex(name, "/"); index != -1 {
+				parentName = name[:index]
+			}
+			section.Parent = getOrCreate(language, parentName)
+			section.Parent.Sections = append(section.Parent.Sections, section)
+		}
+		sections[sectionKey{language, name}] = section
+		return section
+	}
+	root := getOrCreate(config.defaultLanguage(), "")
+
+	sortKeys := map[*Page]pageSortKey{}
+	for i := range pages {
+		page := &pages[i]
+		_, namingFile := getPageLanguage(page.InputPath, config)
+		rel, err := filepath.Rel(config.PagesDir, filepath.Dir(namingFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to determine section of %s: %w", page.InputPath, err)
+		}
+		name := filepath.ToSlash(rel)
+		if name == "." {
+			name = ""
+		}
+
+		// pages of other formats, like feeds, can list their section but are
+		// not listed in it
+		section := getOrCreate(page.Language, name)
+		page.Section = section
+		if page.Format != HTMLFormat {
+			continue
+		}
+
+		key, err := getPageSortKey(page)
+		if err != nil {
+			return nil, fmt.Errorf("invalid frontmatter in page %s: %w", page.InputPath, err)
+		}
+		sortKeys[page] = key
+
+		if filepath.Base(namingFile) == indexPage && section.Index == nil {
+			section.Index = page
+			continue
+		}
+		section.Pages = append(section.Pages, page)
+	}
+
+	// index pages are listed in the section above their own
+	for _, section := range sections {
+		if section.Parent != nil && section.Index != nil {
+			section.Parent.Pages = append(section.Parent.Pages, section.Index)
+		}
+	}
+
+	for _, section := range sections {
+		sort.SliceStable(section.Pages, func(i, j int) bool {
+			return sortKeys[section.Pages[i]].less(sortKeys[section.Pages[j]])
+		})
+		sort.Slice(section.Sections, func(i, j int) bool {
+			return section.Sections[i].Name < section.Sections[j].Name
+		})
+	}
+	return root, nil
+}
+
+type pageSortKey struct {
+	weight    float64
+	hasWeight bool
+	date      time.Time
+	url       string
+}
+
+func getPageSortKey(page *Page) (pageSortKey, error) {
+	key := pageSortKey{url: page.URL}
+	if weight, ok := page.Data[weightKey]; ok {
+		number, ok := weight.(float64)
+		if !ok {
+			return key, fmt.Errorf("%s must be a number", weightKey)
+		}
+		key.weight, key.hasWeight = number, true
+	}
+
+	date, err := getDate(page.Data, dateKey)
+	if err != nil {
+		return key, err
+	}
+	key.date = date
+	return key, nil
+}
+
+// less orders weighted pages first by weight, then newer pages first
+func (k pageSortKey) less(other pageSortKey) bool {
+	if k.hasWeight != other.hasWeight {
+		return k.hasWeight
+	}
+	if k.weight != other.weight {
+		return k.weight < other.weight
+	}
+	if !k.date.Equal(other.date) {
+		return k.date.After(other.date)
+	}
+	return k.url < other.url
+}
+
+// container is the section whose pages list the page, an index page is
+// listed by the section above its own
+func (p *Page) container() *Section {
+	if p.Section == nil || p.Section.Index != p {
+		return p.Section
+	}
+	return p.Section.Parent
+}
+
+// setNavigation sets the parent, ancestors, children, siblings and previous
+// and next pages of the page object.
+func (c *PageContext) setNavigation(page *Page) {
+	c.Section = page.Section
+	if page.Section != nil && page.Section.Index == page {
+		c.Children = page.Section.Pages
+	}
+
+	c.Parent = page.Parent()
+	for parent := c.Parent; parent != nil; parent = parent.Parent() {
+		c.Ancestors = append([]*Page{parent}, c.Ancestors...)
+	}
+
+	container := page.container()
+	if container == nil {
+		return
+	}
+	for i, sibling := range container.Pages {
+		if sibling != page {
+			c.Siblings = append(c.Siblings, sibling)
+			continue
+		}
+		if i > 0 {
+			c.Prev = container.Pages[i-1]
+		}
+		if i < len(container.Pages)-1 {
+			c.Next = container.Pages[i+1]
+		}
+	}
+}
+
+// Parent returns the nearest index page above the page, or nil for the root
+// index page.
+func (p *Page) Parent() *Page {
+	for section := p.container(); section != nil; section = section.Parent {
+		if section.Index != nil && section.Index != p {
+			return section.Index
+		}
+	}
+	return nil
+}
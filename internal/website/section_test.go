@@ -0,0 +1,137 @@
+package website
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadSections(t *testing.T) {
+	config := writeTestSite(t, map[string]string{
+		"pages/layouts/default.tmpl":   `{{block "content" .}}{{end}}`,
+		"pages/index.tmpl":             ``,
+		"pages/about.tmpl":             `{{/* {"weight": 2} */}}`,
+		"pages/feed.xml.tmpl":          ``,
+		"pages/docs/index.tmpl":        `{{/* {"weight": 1} */}}`,
+		"pages/docs/setup.tmpl":        `{{/* {"weight": 1} */}}`,
+		"pages/docs/usage.tmpl":        `{{/* {"weight": 2} */}}`,
+		"pages/docs/api/methods.tmpl":  ``,
+		"pages/blog/old.tmpl":          `{{/* {"date": "2024-01-01"} */}}`,
+		"pages/blog/new.tmpl":          `{{/* {"date": "2025-01-01"} */}}`,
+		"pages/blog/weighted.tmpl":     `{{/* {"date": "2020-01-01", "weight": 5} */}}`,
+		"pages/blog/undated.tmpl":      ``,
+		"pages/blog/2025/recent.tmpl":  ``,
+		"pages/blog/2025/earlier.tmpl": ``,
+	})
+
+	website, err := Load(config)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	urls := func(pages []*Page) string {
+		names := []string{}
+		for _, page := range pages {
+			names = append(names, page.URL)
+		}
+		return strings.Join(names, " ")
+	}
+
+	root := website.Root
+	if root.Index == nil || root.Index.URL != "/" {
+		t.Fatalf("expected the root index page, got %+v", root.Index)
+	}
+	if got := urls(root.Pages); got != "/docs/ /about/" {
+		t.Errorf("unexpected root pages %s", got)
+	}
+	if len(root.Sections) != 2 || root.Sections[0].Name != "blog" || root.Sections[1].Name != "docs" {
+		t.Fatalf("expected blog and docs sections, got %+v", root.Sections)
+	}
+
+	blog := root.Sections[0]
+	if got := urls(blog.Pages); got != "/blog/weighted/ /blog/new/ /blog/old/ /blog/undated/" {
+		t.Errorf("unexpected blog pages %s", got)
+	}
+	if len(blog.Sections) != 1 || blog.Sections[0].Name != "blog/2025" || blog.Sections[0].Parent != blog {
+		t.Errorf("expected nested blog/2025 section, got %+v", blog.Sections)
+	}
+
+	docs := root.Sections[1]
+	if got := urls(docs.Pages); got != "/docs/setup/ /docs/usage/" {
+		t.Errorf("unexpected docs pages %s", got)
+	}
+	methods := docs.Sections[0].Pages[0]
+	if parent := methods.Parent(); parent != docs.Index {
+		t.Errorf("expected docs index as parent of a section without index, got %+v", parent)
+	}
+}
+
+func TestRenderNavigation(t *testing.T) {
+	config := writeTestSite(t, map[string]string{
+		"pages/layouts/default.tmpl": `{{range .Page.Ancestors}}{{.Data.title}} > {{end}}{{.title}}` +
+			`|{{with .Page.Prev}}{{.URL}}{{end}}|{{with .Page.Next}}{{.URL}}{{end}}` +
+			`|{{range .Page.Siblings}}{{.URL}} {{end}}|{{range .Page.Children}}{{.URL}} {{end}}`,
+		"pages/index.tmpl":        `{{/* {"title": "Home"} */}}`,
+		"pages/docs/index.tmpl":   `{{/* {"title": "Docs"} */}}`,
+		"pages/docs/setup.tmpl":   `{{/* {"title": "Setup", "weight": 1} */}}`,
+		"pages/docs/usage.tmpl":   `{{/* {"title": "Usage", "weight": 2} */}}`,
+		"pages/docs/testing.tmpl": `{{/* {"title": "Testing", "weight": 3} */}}`,
+	})
+
+	website, err := Load(config)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := Render(website, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for file, expected := range map[string]string{
+		"index.html":            `Home||||/docs/ `,
+		"docs/index.html":       `Home > Docs||||/docs/setup/ /docs/usage/ /docs/testing/ `,
+		"docs/usage/index.html": `Home > Docs > Usage|/docs/setup/|/docs/testing/|/docs/setup/ /docs/testing/ |`,
+		"docs/setup/index.html": `Home > Docs > Setup||/docs/usage/|/docs/usage/ /docs/testing/ |`,
+	} {
+		output, err := os.ReadFile(filepath.Join(config.OutputDir, filepath.FromSlash(file)))
+		if err != nil {
+			t.Fatalf("expected %s to be rendered, got %v", file, err)
+		}
+		if string(output) != expected {
+			t.Errorf("expected %s for %s, got %s", expected, file, output)
+		}
+	}
+}
+
+func TestRenderSectionIndex(t *testing.T) {
+	config := writeTestSite(t, map[string]string{
+		"pages/layouts/default.tmpl": `{{.Page.URL}}|{{with .Page.Parent}}{{.URL}}{{end}}|{{range .Page.Ancestors}}{{.URL}} {{end}}`,
+		"pages/index.tmpl":           ``,
+		"pages/blog/index.tmpl":      ``,
+		"pages/blog/post.tmpl":       ``,
+	})
+
+	website, err := Load(config)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := Render(website, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for file, expected := range map[string]string{
+		"blog/index.html":      `/blog/|/|/ `,
+		"blog/post/index.html": `/blog/post/|/blog/|/ /blog/ `,
+	} {
+		output, err := os.ReadFile(filepath.Join(config.OutputDir, filepath.FromSlash(file)))
+		if err != nil {
+			t.Fatalf("expected %s to be rendered, got %v", file, err)
+		}
+		if string(output) != expected {
+			t.Errorf("expected %s for %s, got %s", expected, file, output)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(config.OutputDir, "blog", "index", "index.html")); !os.IsNotExist(err) {
+		t.Errorf("expected no blog/index/index.html, got %v", err)
+	}
+}
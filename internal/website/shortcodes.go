@@ -0,0 +1,39 @@
+package website
+
+import (
+	"fmt"
+	"html/template"
+	"path/filepath"
+)
+
+const DefaultShortcodesDir = "shortcodes"
+
+// loadShortcodes parses the shortcode templates of the project and its
+// themes, each named by its path within the shortcodes directory. Project
+// shortcodes override theme shortcodes of the same name.
+func loadShortcodes(config *WebsiteConfig, cache partialsCache) (map[string]*template.Template, error) {
+	sources := []string{filepath.Join(config.PagesDir, DefaultShortcodesDir)}
+	for _, theme := range config.Themes {
+		sources = append(sources, filepath.Join(theme, DefaultShortcodesDir))
+	}
+
+	shortcodes := map[string]*template.Template{}
+	for _, source := range sources {
+		files, err := cache.read(source)
+		if err != nil {
+			return nil, err
+		}
+
+		for name, file := range files {
+			if _, exists := shortcodes[name]; exists {
+				continue
+			}
+			shortcode, err := template.New(filepath.Base(file)).Funcs(TemplateFuncs).ParseFiles(file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse shortcode %s: %w", file, err)
+			}
+			shortcodes[name] = shortcode
+		}
+	}
+	return shortcodes, nil
+}
@@ -0,0 +1,71 @@
+package website
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderShortcodes(t *testing.T) {
+	config := writeTestSite(t, map[string]string{
+		"pages/layouts/default.tmpl":        `{{block "content" .}}{{end}}`,
+		"pages/shortcodes/media/video.tmpl": `<video src="{{.Args.src}}" title="{{.Page.title}}"></video>`,
+		"pages/shortcodes/callout.tmpl":     `<aside>{{.Inner}}</aside>`,
+		"pages/shortcodes/share.tmpl":       `<a href="{{.Page.Page.URL}}" data-section="{{.Page.Page.Section.Name}}">share</a>`,
+		"pages/index.tmpl": `{{/* {"title": "Home"} */}}
+{{define "content"}}{{< media/video src="/a.mp4" >}}{{< callout >}}<b>{{.title}}</b>{{< /callout >}}{{end}}`,
+		"pages/docs/guide.tmpl": `{{define "content"}}{{< share >}}{{end}}`,
+	})
+
+	website, err := Load(config)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(website.Pages) != 2 {
+		t.Fatalf("expected shortcodes not to be loaded as pages, got %d pages", len(website.Pages))
+	}
+
+	if err := Render(website, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	output, err := os.ReadFile(filepath.Join(config.OutputDir, "index.html"))
+	if err != nil {
+		t.Fatalf("expected page to be rendered, got %v", err)
+	}
+	expected := `<video src="/a.mp4" title="Home"></video><aside><b>Home</b></aside>`
+	if string(output) != expected {
+		t.Errorf("expected %s, got %s", expected, output)
+	}
+
+	// shortcodes get the page object as layouts do
+	output, err = os.ReadFile(filepath.Join(config.OutputDir, "docs", "guide", "index.html"))
+	if err != nil {
+		t.Fatalf("expected page to be rendered, got %v", err)
+	}
+	expected = `<a href="/docs/guide/" data-section="docs">share</a>`
+	if string(output) != expected {
+		t.Errorf("expected %s, got %s", expected, output)
+	}
+}
+
+func TestRenderUnknownShortcode(t *testing.T) {
+	config := writeTestSite(t, map[string]string{
+		"pages/layouts/default.tmpl": `{{block "content" .}}{{end}}`,
+		"pages/index.tmpl": `{{/*
+{"title": "Home"}
+*/}}
+{{define "content"}}
+  {{< gallery >}}{{end}}`,
+	})
+
+	website, err := Load(config)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	err = Render(website, "")
+	if err == nil || !strings.HasSuffix(err.Error(), `index.tmpl:5:3: unknown shortcode "gallery"`) {
+		t.Fatalf("expected unknown shortcode error with location, got %v", err)
+	}
+}
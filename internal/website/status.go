@@ -0,0 +1,80 @@
+package website
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	StatusDraft   = "draft"
+	StatusFuture  = "future"
+	StatusExpired = "expired"
+
+	draftKey       = "draft"
+	publishDateKey = "publishDate"
+	expiryDateKey  = "expiryDate"
+)
+
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// getPageStatus reports why a page should not be published, or an empty
+// string when the page is live.
+func getPageStatus(pageData map[string]any, at time.Time) (string, error) {
+	if draft, ok := pageData[draftKey]; ok {
+		isDraft, ok := draft.(bool)
+		if !ok {
+			return "", fmt.Errorf("%s must be true or false", draftKey)
+		}
+		if isDraft {
+			return StatusDraft, nil
+		}
+	}
+
+	publishDate, err := getDate(pageData, publishDateKey)
+	if err != nil {
+		return "", err
+	}
+	if !publishDate.IsZero() && publishDate.After(at) {
+		return StatusFuture, nil
+	}
+
+	expiryDate, err := getDate(pageData, expiryDateKey)
+	if err != nil {
+		return "", err
+	}
+	if !expiryDate.IsZero() && !expiryDate.After(at) {
+		return StatusExpired, nil
+	}
+
+	return "", nil
+}
+
+func getDate(pageData map[string]any, key string) (time.Time, error) {
+	value, ok := pageData[key]
+	if !ok {
+		return time.Time{}, nil
+	}
+
+	date, ok := value.(string)
+	if !ok {
+		return time.Time{}, fmt.Errorf("%s must be a date string", key)
+	}
+
+	for _, layout := range dateLayouts {
+		if parsed, err := time.ParseInLocation(layout, date, time.Local); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%s %q is not a valid date, use YYYY-MM-DD or RFC 3339", key, date)
+}
+
+func statusBadge(status string) string {
+	return fmt.Sprintf(`<div class="ditto-status" style="position:fixed;top:0;right:0;z-index:2147483647;`+
+		`padding:4px 8px;background:#c62828;color:#fff;font:bold 12px sans-serif;text-transform:uppercase">%s</div>`,
+		status)
+}
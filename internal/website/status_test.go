@@ -0,0 +1,97 @@
+package website
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetPageStatus(t *testing.T) {
+	at := time.Date(2025, 6, 1, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		data     map[string]any
+		expected string
+	}{
+		{map[string]any{}, ""},
+		{map[string]any{"draft": false}, ""},
+		{map[string]any{"draft": true}, StatusDraft},
+		{map[string]any{"publishDate": "2025-05-31"}, ""},
+		{map[string]any{"publishDate": "2025-06-02"}, StatusFuture},
+		{map[string]any{"publishDate": "2025-06-01T13:00:00"}, StatusFuture},
+		{map[string]any{"expiryDate": "2025-06-01"}, StatusExpired},
+		{map[string]any{"expiryDate": "2025-07-01T00:00:00Z"}, ""},
+		{map[string]any{"draft": true, "expiryDate": "2020-01-01"}, StatusDraft},
+	}
+
+	for _, test := range tests {
+		status, err := getPageStatus(test.data, at)
+		if err != nil {
+			t.Fatalf("expected no error for %v, got %v", test.data, err)
+		}
+		if status != test.expected {
+			t.Errorf("expected status %q for %v, got %q", test.expected, test.data, status)
+		}
+	}
+}
+
+func TestGetPageStatusInvalidDate(t *testing.T) {
+	_, err := getPageStatus(map[string]any{"publishDate": "next tuesday"}, time.Now())
+	if err == nil || !strings.Contains(err.Error(), "publishDate") {
+		t.Fatalf("expected publishDate error, got %v", err)
+	}
+}
+
+func TestLoadExcludesUnpublishedPages(t *testing.T) {
+	files := map[string]string{
+		"pages/layouts/default.tmpl": `<body>{{block "content" .}}{{end}}</body>`,
+		"pages/index.tmpl":           `{{define "content"}}home{{end}}`,
+		"pages/draft.tmpl":           `{{/* {"draft": true} */}}{{define "content"}}draft{{end}}`,
+		"pages/future.tmpl":          `{{/* {"publishDate": "2999-01-01"} */}}`,
+		"pages/expired.tmpl":         `{{/* {"expiryDate": "2000-01-01"} */}}`,
+	}
+
+	config := writeTestSite(t, files)
+	website, err := Load(config)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(website.Pages) != 1 {
+		t.Fatalf("expected only the published page, got %d pages", len(website.Pages))
+	}
+
+	config.IncludeDrafts = true
+	website, err = Load(config)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(website.Pages) != 4 {
+		t.Fatalf("expected all pages with drafts included, got %d pages", len(website.Pages))
+	}
+
+	if err := Render(website, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	output, err := os.ReadFile(filepath.Join(config.OutputDir, "draft", "index.html"))
+	if err != nil {
+		t.Fatalf("expected draft to be rendered, got %v", err)
+	}
+	if !strings.HasPrefix(string(output), "<body>draft<div class=\"ditto-status\"") ||
+		!strings.HasSuffix(string(output), "</div></body>") {
+		t.Errorf("expected draft badge before </body>, got %s", output)
+	}
+}
+
+func TestInsertBeforeBodyEnd(t *testing.T) {
+	// the dotted capital i is longer once lowercased
+	text := strings.Repeat("İ", 16)
+	for content, expected := range map[string]string{
+		"<body>" + text + "</BODY></html>": "<body>" + text + "<b></BODY></html>",
+		"<p>" + text + "</p>":              "<p>" + text + "</p><b>",
+	} {
+		if got := string(insertBeforeBodyEnd([]byte(content), "<b>")); got != expected {
+			t.Errorf("expected %s, got %s", expected, got)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package website
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	DefaultStaticDir = "static"
+	ThemeLayoutsDir  = "layouts"
+)
+
+// getLayoutFiles collects layouts and partials from the project layouts
+// directory and then each theme in order. A file is identified by its name,
+// so the project overrides themes and earlier themes override later ones.
+func getLayoutFiles(config *WebsiteConfig) ([]string, []string, error) {
+	sources := []string{filepath.Join(config.PagesDir, DefaultLayoutsDir)}
+	for _, theme := range config.Themes {
+		sources = append(sources, filepath.Join(theme, ThemeLayoutsDir))
+	}
+
+	files := map[string]string{}
+	for _, source := range sources {
+		if _, err := os.Stat(source); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+
+		sourceFiles, err := getFilesRecursive(source, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, file := range sourceFiles {
+			name := filepath.Base(file)
+			if _, exists := files[name]; !exists {
+				files[name] = file
+			}
+		}
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// separate layouts from partials
+	layoutFiles := []string{}
+	partialFiles := []string{}
+	for _, name := range names {
+		if strings.HasPrefix(name, "_") {
+			partialFiles = append(partialFiles, files[name])
+		} else {
+			layoutFiles = append(layoutFiles, files[name])
+		}
+	}
+	return layoutFiles, partialFiles, nil
+}
+
+// getDataDirs returns the directories searched for data files, the project
+// data directory first followed by the data directory of each theme.
+func getDataDirs(config *WebsiteConfig) []string {
+	dirs := []string{config.DataDir}
+	for _, theme := range config.Themes {
+		dirs = append(dirs, filepath.Join(theme, DefaultDataDir))
+	}
+	return dirs
+}
+
+func findDataFile(source string, config *WebsiteConfig) (string, error) {
+	for _, dir := range getDataDirs(config) {
+		dataFile := filepath.Join(dir, source)
+		if _, err := os.Stat(dataFile); err == nil {
+			return dataFile, nil
+		}
+	}
+	return "", fmt.Errorf("data file %s not found", source)
+}
+
+// getStaticDirs returns the static asset directories in the order they are
+// copied, so the project static directory is copied last and wins.
+func getStaticDirs(config *WebsiteConfig) []string {
+	dirs := []string{}
+	for i := len(config.Themes) - 1; i >= 0; i-- {
+		dirs = append(dirs, filepath.Join(config.Themes[i], DefaultStaticDir))
+	}
+	return append(dirs, config.StaticDir)
+}
+
+func copyStaticFiles(config *WebsiteConfig) error {
+	for _, dir := range getStaticDirs(config) {
+		if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+
+		err := filepath.WalkDir(dir, func(file string, d os.DirEntry, err error) error {
+			if err != nil {
+				return fmt.Errorf("failed to walk files in '%s': %w", dir, err)
+			}
+			if d.IsDir() {
+				return nil
+			}
+
+			rel, err := filepath.Rel(dir, file)
+			if err != nil {
+				return err
+			}
+			if shouldMinifyStatic(rel, config) {
+				return minifyFile(file, filepath.Join(config.OutputDir, rel), config)
+			}
+			return copyOutput(config, file, filepath.Join(config.OutputDir, rel))
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package website
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadWithThemes(t *testing.T) {
+	config := writeTestSite(t, map[string]string{
+		"pages/layouts/_nav.tmpl":           `{{define "nav"}}project nav{{end}}`,
+		"pages/index.tmpl":                  `{{define "content"}}{{.title}}{{end}}`,
+		"pages/product.tmpl":                `{{/* {"data": "products.json", "permalink": "/p/{{.slug}}/"} */}}`,
+		"themes/brand/layouts/_nav.tmpl":    `{{define "nav"}}brand nav{{end}}`,
+		"themes/brand/layouts/default.tmpl": `{{template "nav"}} {{template "footer"}} {{block "content" .}}{{end}}`,
+		"themes/brand/static/css/site.css":  `brand`,
+		"themes/base/layouts/_footer.tmpl":  `{{define "footer"}}base footer{{end}}`,
+		"themes/base/layouts/default.tmpl":  `base layout`,
+		"themes/base/static/css/site.css":   `base`,
+		"themes/base/static/logo.svg":       `<svg/>`,
+		"themes/base/data/products.json":    `[{"slug": "chair"}]`,
+		"static/logo.svg":                   `<svg id="project"/>`,
+	})
+	root := filepath.Dir(config.PagesDir)
+	config.Themes = []string{filepath.Join(root, "themes", "brand"), filepath.Join(root, "themes", "base")}
+
+	website, err := Load(config)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(website.Pages) != 2 {
+		t.Fatalf("expected index and data page, got %d pages", len(website.Pages))
+	}
+	if website.LayoutFiles["default.tmpl"] != filepath.ToSlash(filepath.Join(root, "themes", "brand", "layouts", "default.tmpl")) {
+		t.Errorf("expected default layout from the first theme, got %s", website.LayoutFiles["default.tmpl"])
+	}
+
+	if err := Render(website, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := map[string]string{
+		"index.html":   "project nav base footer ",
+		"css/site.css": "brand",
+		"logo.svg":     `<svg id="project"/>`,
+	}
+	for name, content := range expected {
+		output, err := os.ReadFile(filepath.Join(config.OutputDir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatalf("expected %s to be written, got %v", name, err)
+		}
+		if string(output) != content {
+			t.Errorf("expected %s to contain %q, got %q", name, content, output)
+		}
+	}
+}
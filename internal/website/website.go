@@ -1,194 +1,675 @@
 package website
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
+	"io"
+	"maps"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
+	texttemplate "text/template"
+	"time"
 
+	"github.com/eastcitysoftware/ditto/internal/highlight"
+	"github.com/eastcitysoftware/ditto/internal/memfs"
+	"github.com/eastcitysoftware/ditto/internal/minify"
 	"github.com/eastcitysoftware/ditto/internal/render"
 )
 
 const (
+	ConfigFile        = "ditto.json"
 	TmplExtension     = ".tmpl"
 	DefaultPagesDir   = "pages"
 	DefaultLayoutsDir = "layouts"
 	DefaultOutputDir  = "public"
+	DefaultDataDir    = "data"
+	DefaultMocksDir   = "mocks"
 	DefaultLayout     = "default.tmpl"
+	HTMLFormat        = ".html"
+
+	layoutKey = "layout"
 )
 
 type WebsiteConfig struct {
-	PagesDir      string
-	DefaultLayout string
-	OutputDir     string
+	PagesDir      string `json:"-"`
+	DataDir       string `json:"-"`
+	StaticDir     string `json:"-"`
+	I18nDir       string `json:"-"`
+	CacheDir      string `json:"-"`
+	AssetsDir     string `json:"-"`
+	MocksDir      string `json:"-"`
+	DefaultLayout string `json:"-"`
+	OutputDir     string `json:"-"`
+	IncludeDrafts bool   `json:"-"`
+
+	// options read from the project config file
+	Permalinks    map[string]string `json:"permalinks"`
+	UglyURLs      bool              `json:"uglyURLs"`
+	RedirectsFile bool              `json:"redirectsFile"`
+
+	Schemas map[string]SectionSchema `json:"schemas"`
+
+	// themes are stacked, earlier themes override later ones
+	Themes []string `json:"themes"`
+
+	// highlight fenced code blocks in pages and language-x code blocks in
+	// rendered html
+	HighlightCodeBlocks bool `json:"highlightCodeBlocks"`
+
+	// named menus, pages add themselves with the menu frontmatter key
+	Menus map[string][]*MenuEntry `json:"menus"`
+
+	// languages of a multilingual site, the first is the default
+	Languages []Language `json:"languages"`
+
+	// minify rendered pages and static assets, except static files matching
+	// the exclude patterns and pages setting minify to false
+	Minify        bool     `json:"minify"`
+	MinifyExclude []string `json:"minifyExclude"`
+
+	// css and javascript bundles built from entry points in the assets
+	// directory, fingerprinted outputs have the content hash in their name
+	Bundles     []BundleConfig `json:"bundles"`
+	Fingerprint bool           `json:"fingerprint"`
+
+	// write a json search index of the html pages
+	Search *SearchConfig `json:"search"`
+
+	// development server behaviour, to match the production host, read by
+	// the serve command
+	Server json.RawMessage `json:"server"`
+
+	// when set, output is written to memory instead of the output directory
+	Memory *memfs.FS `json:"-"`
+
+	// files written to the output directory since the last full render
+	outputs outputSet
 }
 
 type Website struct {
-	OutputDir string
-	Layouts   map[string]*template.Template
-	Pages     []Page
+	Config      *WebsiteConfig
+	OutputDir   string
+	Layouts     map[string]*template.Template
+	LayoutFiles map[string]string
+	Partials    map[string]string
+	Shortcodes  map[string]*template.Template
+	Pages       []Page
+	Root        *Section
+	Menus       map[string][]*MenuEntry
+	Redirects   []Redirect
+
+	// translation strings by language, for the T template func
+	TranslationTables map[string]map[string]string
+
+	// bundles by their configured output path, for the asset template func
+	Bundles map[string]*Bundle
+
+	images   *imageProcessor
+	search   *searchIndex
+	allPages []*Page
 }
 
 type Page struct {
 	Name       string
+	URL        string
 	Layout     string
 	InputPath  string
 	OutputPath string
+	Format     string
+	Status     string
+	Section    *Section
+	Language   string
+	Partials   map[string]string
+	Data       map[string]any
+
+	// translations of a page share a translation key
+	TranslationKey string
 }
 
 func Render(website *Website, fileToRender string) error {
+	// in memory a full render writes new files that replace the served ones
+	// when it is complete, so requests during a rebuild never see a partly
+	// written site and a failed render keeps the last good one
+	live := website.Config.Memory
+	if fileToRender != "" || live == nil {
+		return renderWebsite(website, fileToRender)
+	}
+
+	website.Config.Memory = memfs.New()
+	err := renderWebsite(website, fileToRender)
+	if err == nil {
+		live.Replace(website.Config.Memory)
+	}
+	website.Config.Memory = live
+	return err
+}
+
+func renderWebsite(website *Website, fileToRender string) error {
 	if fileToRender == "" {
 		// clean output directory
-		err := removeFileRecursive(website.OutputDir, "index.html")
-		if err != nil {
+		if err := cleanOutput(website.Config); err != nil {
+			return err
+		}
+
+		if err := copyStaticFiles(website.Config); err != nil {
+			return err
+		}
+
+		if err := writeBundles(website.Bundles, website.Config); err != nil {
 			return err
 		}
+		website.search.reset()
 	}
 
-	// render pages
-	for _, page := range website.Pages {
+	// render pages, by reference so the section tree finds the page
+	for i := range website.Pages {
+		page := &website.Pages[i]
 		if fileToRender != "" && fileToRender != page.InputPath {
 			continue
 		}
 
-		if website.Layouts[page.Layout] == nil {
+		// only html pages are rendered through a layout
+		layout := website.Layouts[page.Layout]
+		if layout == nil && page.Format == HTMLFormat {
 			return fmt.Errorf("layout %s not found for page %s", page.Layout, page.InputPath)
 		}
 
-		renderPage(page, website.Layouts[page.Layout])
+		// section partials are only added to the layout of pages within it
+		if len(page.Partials) > 0 && layout != nil {
+			scoped, err := layout.Clone()
+			if err != nil {
+				return fmt.Errorf("failed to clone layout %s: %w", page.Layout, err)
+			}
+			if err := addPartials(scoped, page.Partials); err != nil {
+				return err
+			}
+			layout = scoped
+		}
+
+		if err := renderPage(website, page, layout); err != nil {
+			return err
+		}
 	}
 
-	return nil
+	// render redirects from page aliases
+	for _, redirect := range website.Redirects {
+		if fileToRender != "" && fileToRender != redirect.InputPath {
+			continue
+		}
+
+		if err := renderRedirect(redirect, website.Config); err != nil {
+			return err
+		}
+	}
+
+	if fileToRender == "" && website.Config.RedirectsFile {
+		if err := renderRedirectsFile(website.Redirects, website.Config); err != nil {
+			return err
+		}
+	}
+
+	// the index needs every page, so it is only written by a full render
+	if fileToRender == "" {
+		if err := website.search.write(website.Config); err != nil {
+			return err
+		}
+	}
+
+	return saveOutputs(website.Config)
 }
 
-func renderPage(page Page, layout *template.Template) error {
-	pageReader, err := os.Open(page.InputPath)
+func renderPage(website *Website, page *Page, layout *template.Template) error {
+	pageFile, err := os.ReadFile(page.InputPath)
 	if err != nil {
 		return fmt.Errorf("failed to open page file %s: %w", page.InputPath, err)
 	}
 
-	if err := os.MkdirAll(filepath.Dir(page.OutputPath), os.ModePerm); err != nil {
-		return fmt.Errorf("failed to create output directory %s: %w", page.OutputPath, err)
+	// frontmatter was read during load, only the template content is needed
+	pageContent, _, err := render.ReadPage(bytes.NewReader(pageFile))
+	if err != nil {
+		return fmt.Errorf("failed to read page %s: %w", page.InputPath, err)
 	}
 
-	outputFile, err := os.Create(page.OutputPath)
+	// shortcodes are expanded before the page is parsed as a template, with
+	// the data the layout gets, the frontmatter lines are counted so errors
+	// point at the page file
+	data, context := getTemplateData(website, page)
+	frontmatterLines := bytes.Count(pageFile[:len(pageFile)-len(pageContent)], []byte("\n"))
+	pageContent, err = render.ExpandShortcodes(pageContent, page.InputPath, frontmatterLines, website.Shortcodes, data)
 	if err != nil {
-		return fmt.Errorf("failed to create output file %s: %w", page.OutputPath, err)
+		return err
 	}
-	defer outputFile.Close()
 
-	err = render.RenderNamedTemplate(pageReader, outputFile, page.Layout, layout)
+	var output bytes.Buffer
+	if page.Format == HTMLFormat {
+		err = renderHTMLPage(&output, website, pageContent, data, context, page, layout)
+	} else {
+		err = renderTextPage(&output, website, pageContent, data, page)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to render page %s: %w", page.InputPath, err)
 	}
-	return nil
+
+	content := output.Bytes()
+	if website.Config.HighlightCodeBlocks && page.Format == HTMLFormat {
+		content = []byte(highlight.HighlightBlocks(string(content)))
+	}
+
+	if shouldMinifyPage(page, website.Config) {
+		minified, err := minify.Minify(page.Format, string(content))
+		if err != nil {
+			return fmt.Errorf("failed to minify page %s: %w", page.InputPath, err)
+		}
+		content = []byte(minified)
+	}
+
+	// unpublished pages are only rendered when drafts are included, badge
+	// them so they are not mistaken for live content
+	if page.Status != "" && page.Format == HTMLFormat {
+		content = insertBeforeBodyEnd(content, statusBadge(page.Status))
+	}
+
+	return writeOutput(website.Config, page.OutputPath, content)
+}
+
+// renderHTMLPage renders the content block first so that its headings, word
+// count and summary are on the page object when the layout is rendered.
+func renderHTMLPage(wr io.Writer, website *Website, pageContent string, data map[string]any, context *PageContext, page *Page, layout *template.Template) error {
+	pageTemplate, err := render.ParsePage(pageContent, layout)
+	if err != nil {
+		return err
+	}
+	pageTemplate.Funcs(template.FuncMap{
+		translateFunc: website.translate(page.Language),
+		imageFunc:     website.images.open,
+		assetFunc:     website.asset,
+	})
+
+	var content bytes.Buffer
+	hasContent, err := render.RenderContent(&content, pageTemplate, data)
+	if err != nil {
+		return err
+	}
+	if hasContent {
+		html, info := render.AnalyzeContent(content.String())
+		if err := render.ReplaceContent(pageTemplate, html); err != nil {
+			return err
+		}
+		context.setContentInfo(info, page.Data)
+		website.search.add(page, html, info)
+	} else {
+		website.search.add(page, "", render.ContentInfo{})
+	}
+
+	return pageTemplate.ExecuteTemplate(wr, page.Layout, data)
+}
+
+// renderTextPage renders a page for another output format, with the funcs
+// and partials that html pages have.
+func renderTextPage(wr io.Writer, website *Website, pageContent string, data map[string]any, page *Page) error {
+	partials, err := readPartials(website.Partials, page.Partials)
+	if err != nil {
+		return err
+	}
+
+	funcs := texttemplate.FuncMap(maps.Clone(TemplateFuncs))
+	funcs[translateFunc] = website.translate(page.Language)
+	funcs[imageFunc] = website.images.open
+	funcs[assetFunc] = website.asset
+	return render.RenderText(wr, pageContent, data, page.Format, funcs, partials)
+}
+
+func insertBeforeBodyEnd(content []byte, html string) []byte {
+	// search backwards in content itself, lowercasing can change its length
+	index := -1
+	end := []byte("</body>")
+	for i := len(content) - len(end); i >= 0; i-- {
+		if bytes.EqualFold(content[i:i+len(end)], end) {
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		return append(content, html...)
+	}
+
+	result := make([]byte, 0, len(content)+len(html))
+	result = append(result, content[:index]...)
+	result = append(result, html...)
+	return append(result, content[index:]...)
 }
 
 func Load(config *WebsiteConfig) (*Website, error) {
-	// get layout files
-	layoutsDir := filepath.Join(config.PagesDir, "layouts")
-	allLayoutfiles, err := getFilesRecursive(layoutsDir, nil)
+	if err := checkSchemas(config.Schemas); err != nil {
+		return nil, err
+	}
+
+	// get layout and partial files from the project and its themes
+	layoutsDir := filepath.Join(config.PagesDir, DefaultLayoutsDir)
+	layoutFiles, partialFiles, err := getLayoutFiles(config)
 	if err != nil {
 		return nil, err
 	}
 
-	// separate layouts from partials
-	layoutFiles := []string{}
-	partialFiles := []string{}
-	for _, layoutFile := range allLayoutfiles {
-		filename := filepath.Base(layoutFile)
-		if strings.HasPrefix(filename, "_") {
-			partialFiles = append(partialFiles, layoutFile)
-		} else {
-			layoutFiles = append(layoutFiles, layoutFile)
-		}
+	// partials in the partials directories are named by their path
+	partialsByDir := partialsCache{}
+	sharedPartials, err := getSharedPartials(config, partialsByDir)
+	if err != nil {
+		return nil, err
+	}
+
+	partials := map[string]string{}
+	for _, partialFile := range partialFiles {
+		partials[filepath.Base(partialFile)] = partialFile
+	}
+	for name, partialFile := range sharedPartials {
+		partials[name] = partialFile
 	}
 
 	// build layout map
 	layouts := map[string]*template.Template{}
+	layoutFilesByName := map[string]string{}
 	for _, layoutFile := range layoutFiles {
 		layoutName := filepath.Base(layoutFile)
-		layout, err := template.ParseFiles(append(partialFiles, layoutFile)...)
+		layoutFilesByName[layoutName] = layoutFile
+		files := append(partialFiles, layoutFile)
+		layout, err := template.New(filepath.Base(files[0])).Funcs(TemplateFuncs).ParseFiles(files...)
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse layout file %s: %w", layoutFile, err)
 		}
+		if err := addPartials(layout, sharedPartials); err != nil {
+			return nil, err
+		}
 		layouts[layoutName] = layout
 	}
 
+	shortcodes, err := loadShortcodes(config, partialsByDir)
+	if err != nil {
+		return nil, err
+	}
+
 	// get page files
-	pageFiles, err := getFilesRecursive(config.PagesDir, []string{layoutsDir})
+	shortcodesDir := filepath.Join(config.PagesDir, DefaultShortcodesDir)
+	pageFiles, err := getFilesRecursive(config.PagesDir, []string{layoutsDir, shortcodesDir})
 	if err != nil {
 		return nil, err
 	}
 
 	pages := []Page{}
+	publishedAt := time.Now()
+	schemaErrs := []error{}
+	defaults := defaultsCache{}
 
 	for _, pageFile := range pageFiles {
-		pageName, err := getPageName(pageFile, config.PagesDir)
+		if isPartialFile(pageFile, config.PagesDir) {
+			continue
+		}
 
+		pageData, err := readFrontmatter(pageFile)
 		if err != nil {
 			return nil, err
 		}
 
-		// Normalize pageName to ensure it works correctly with filepath.Join
-		normalizedPageName := filepath.FromSlash(pageName)
+		// translations are named and laid out as the page without its language
+		language, namingFile := getPageLanguage(pageFile, config)
+
+		// directory defaults cascade into the frontmatter
+		pageData, err = applyDefaults(namingFile, pageData, config.PagesDir, defaults)
+		if err != nil {
+			return nil, err
+		}
 
 		// check if layout exists for page
 		layout := DefaultLayout
-		layoutFromFile := filepath.Base(pageFile)
+		layoutFromFile := filepath.Base(namingFile)
 		if _, exists := layouts[layoutFromFile]; exists {
 			layout = layoutFromFile
 		}
 
-		layoutFromParent := filepath.Base(filepath.Dir(pageFile)) + TmplExtension
+		layoutFromParent := filepath.Base(filepath.Dir(namingFile)) + TmplExtension
 		if _, exists := layouts[layoutFromParent]; exists {
 			layout = layoutFromParent
 		}
 
-		pages = append(pages, Page{
-			Name:       normalizedPageName,
-			Layout:     layout,
-			InputPath:  pageFile,
-			OutputPath: filepath.Join(config.OutputDir, normalizedPageName)})
+		// a layout named in the frontmatter takes precedence
+		if layoutFromData, ok := pageData[layoutKey].(string); ok && layoutFromData != "" {
+			layout = getLayoutName(layoutFromData)
+			if _, exists := layouts[layout]; !exists {
+				return nil, fmt.Errorf("layout %s not found for page %s", layout, pageFile)
+			}
+		}
+
+		scopedPartials, err := getScopedPartials(pageFile, config.PagesDir, partialsByDir)
+		if err != nil {
+			return nil, err
+		}
+
+		// pages declaring a data source expand into one page per item
+		if _, ok := pageData[dataKey]; ok {
+			dataPages, err := expandDataPage(pageFile, layout, pageData, config)
+			if err != nil {
+				return nil, err
+			}
+			for _, dataPage := range dataPages {
+				// items are checked like pages, their fields come from data
+				if err := validateFrontmatter(pageFile, dataPage.Data, config); err != nil {
+					schemaErrs = append(schemaErrs, fmt.Errorf("data page %s: %w", dataPage.URL, err))
+					continue
+				}
+				dataPage.Partials = scopedPartials
+				localizePage(&dataPage, language, config)
+				pages = append(pages, dataPage)
+			}
+			continue
+		}
+
+		// report every schema violation at once rather than one per load
+		if err := validateFrontmatter(pageFile, pageData, config); err != nil {
+			schemaErrs = append(schemaErrs, err)
+			continue
+		}
+
+		pageName, err := resolvePageName(namingFile, pageData, config)
+		if err != nil {
+			return nil, err
+		}
+
+		page := newPage(pageName, layout, pageFile, namingFile, pageData, config)
+		page.Partials = scopedPartials
+		localizePage(&page, language, config)
+		pages = append(pages, page)
+	}
+
+	if err := errors.Join(schemaErrs...); err != nil {
+		return nil, err
+	}
+
+	// drop unpublished pages so they never reach the output
+	pages, err = filterPublished(pages, publishedAt, config.IncludeDrafts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkDuplicateOutputs(pages); err != nil {
+		return nil, err
+	}
+
+	redirects, err := getRedirects(pages, config)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := getSections(pages, config)
+	if err != nil {
+		return nil, err
+	}
+	allPages := getAllPages(pages)
+
+	menus, err := getMenus(pages, config)
+	if err != nil {
+		return nil, err
+	}
+
+	translationTables, err := getTranslationTables(config)
+	if err != nil {
+		return nil, err
+	}
+
+	bundles, err := getBundles(config)
+	if err != nil {
+		return nil, err
+	}
+
+	search, err := newSearchIndex(config.Search)
+	if err != nil {
+		return nil, err
 	}
 
 	website := &Website{
-		OutputDir: config.OutputDir,
-		Layouts:   layouts,
-		Pages:     pages}
+		Config:      config,
+		OutputDir:   config.OutputDir,
+		Layouts:     layouts,
+		LayoutFiles: layoutFilesByName,
+		Partials:    partials,
+		Shortcodes:  shortcodes,
+		Pages:       pages,
+		Root:        root,
+		Menus:       menus,
+		Redirects:   redirects,
+
+		TranslationTables: translationTables,
+		Bundles:           bundles,
+		images:            newImageProcessor(config),
+		search:            search,
+		allPages:          allPages}
 
 	return website, nil
 }
 
-func NewConfig(root string) (*WebsiteConfig, error) {
-	// establish and check directories
-	outputDir := filepath.Join(root, DefaultOutputDir)
-	_, err := os.Stat(outputDir)
+// newPage creates a page, namingFile is the page file without its language
+// as returned by getPageLanguage
+func newPage(pageName string, layout string, pageFile string, namingFile string, pageData map[string]any, config *WebsiteConfig) Page {
+	// ugly urls write every pretty dir/index.html page as dir.html, index
+	// pages keep the index of their directory
+	if config.UglyURLs && filepath.Base(namingFile) != indexPage {
+		pageName = getUglyPageName(pageName)
+	}
+
+	// Normalize pageName to ensure it works correctly with filepath.Join
+	normalizedPageName := filepath.FromSlash(pageName)
+
+	return Page{
+		Name:       normalizedPageName,
+		URL:        getPageURL(pageName),
+		Layout:     layout,
+		InputPath:  pageFile,
+		OutputPath: filepath.Join(config.OutputDir, normalizedPageName),
+		Format:     path.Ext(pageName),
+		Data:       pageData}
+}
+
+func filterPublished(pages []Page, at time.Time, includeDrafts bool) ([]Page, error) {
+	published := []Page{}
+	for _, page := range pages {
+		status, err := getPageStatus(page.Data, at)
+		if err != nil {
+			return nil, fmt.Errorf("invalid frontmatter in page %s: %w", page.InputPath, err)
+		}
+
+		if status != "" && !includeDrafts {
+			continue
+		}
+
+		page.Status = status
+		published = append(published, page)
+	}
+	return published, nil
+}
+
+func readFrontmatter(pageFile string) (map[string]any, error) {
+	pageReader, err := os.Open(pageFile)
 	if err != nil {
-		return nil, fmt.Errorf("public directory does not exist")
+		return nil, fmt.Errorf("failed to open page file %s: %w", pageFile, err)
 	}
+	defer pageReader.Close()
 
-	pagesPath := filepath.Join(root, DefaultPagesDir)
-	_, err = os.Stat(pagesPath)
+	_, pageData, err := render.ReadPage(pageReader)
 	if err != nil {
-		return nil, fmt.Errorf("pages directory does not exist")
+		return nil, fmt.Errorf("failed to read page %s: %w", pageFile, err)
+	}
+	if pageData == nil {
+		pageData = map[string]any{}
 	}
+	return pageData, nil
+}
 
-	layoutsDir := filepath.Join(pagesPath, DefaultLayoutsDir)
-	_, err = os.Stat(layoutsDir)
+func NewConfig(root string) (*WebsiteConfig, error) {
+	// establish and check directories, the output directory is checked by
+	// the commands that write to it
+	outputDir := filepath.Join(root, DefaultOutputDir)
+	pagesPath := filepath.Join(root, DefaultPagesDir)
+	_, err := os.Stat(pagesPath)
 	if err != nil {
-		return nil, fmt.Errorf("layouts directory does not exist")
+		return nil, fmt.Errorf("pages directory does not exist")
 	}
 
 	config := &WebsiteConfig{
 		PagesDir:      pagesPath,
+		DataDir:       filepath.Join(root, DefaultDataDir),
+		StaticDir:     filepath.Join(root, DefaultStaticDir),
+		I18nDir:       filepath.Join(root, DefaultI18nDir),
+		CacheDir:      filepath.Join(root, DefaultCacheDir),
+		AssetsDir:     filepath.Join(root, DefaultAssetsDir),
+		MocksDir:      filepath.Join(root, DefaultMocksDir),
 		DefaultLayout: DefaultLayout,
 		OutputDir:     outputDir,
 	}
+
+	if err := readConfigFile(filepath.Join(root, ConfigFile), config); err != nil {
+		return nil, err
+	}
+
+	// theme paths are relative to the project root
+	for i, theme := range config.Themes {
+		if !filepath.IsAbs(theme) {
+			theme = filepath.Join(root, theme)
+		}
+		if _, err := os.Stat(theme); err != nil {
+			return nil, fmt.Errorf("theme directory %s does not exist", theme)
+		}
+		config.Themes[i] = theme
+	}
+
+	// layouts may come entirely from themes
+	layoutsDir := filepath.Join(pagesPath, DefaultLayoutsDir)
+	_, err = os.Stat(layoutsDir)
+	if err != nil && len(config.Themes) == 0 {
+		return nil, fmt.Errorf("layouts directory does not exist")
+	}
 	return config, nil
 }
 
+func readConfigFile(configFile string, config *WebsiteConfig) error {
+	// the config file is optional
+	content, err := os.ReadFile(configFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if err := json.Unmarshal(content, config); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", configFile, err)
+	}
+	return nil
+}
+
 func getPageName(page string, pagesPath string) (string, error) {
 	// strip .tmpl extension and add .html extension
 	rel, err := filepath.Rel(pagesPath, page)
@@ -197,8 +678,14 @@ func getPageName(page string, pagesPath string) (string, error) {
 	}
 	base := strings.TrimSuffix(rel, filepath.Ext(rel))
 
-	if base == "index" {
-		base = base + ".html"
+	// pages named for their output format, like feed.xml.tmpl, keep their name
+	if isFormatName(base) {
+		return filepath.ToSlash(base), nil
+	}
+
+	// section index pages render to the index of their directory
+	if filepath.Base(base) == "index" {
+		base = filepath.Join(filepath.Dir(base), "index.html")
 	} else {
 		base = filepath.Join(base, "index.html")
 	}
@@ -206,6 +693,30 @@ func getPageName(page string, pagesPath string) (string, error) {
 	return filepath.ToSlash(base), nil
 }
 
+func getLayoutName(layout string) string {
+	if filepath.Ext(layout) != TmplExtension {
+		layout += TmplExtension
+	}
+	return layout
+}
+
+func getUglyPageName(pageName string) string {
+	if pageName == "index.html" || !strings.HasSuffix(pageName, "/index.html") {
+		return pageName
+	}
+	return strings.TrimSuffix(pageName, "/index.html") + HTMLFormat
+}
+
+func isFormatName(name string) bool {
+	ext := filepath.Ext(name)
+	return ext == HTMLFormat || render.IsTextFormat(ext)
+}
+
+func getPageURL(pageName string) string {
+	// pretty urls drop the trailing index.html
+	return "/" + strings.TrimSuffix(pageName, "index.html")
+}
+
 // func getLayoutName(page string, layouts []string) string {
 // 	// if layouts contains a template with the same name as the page
 // 	// directory, use that template
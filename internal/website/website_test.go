@@ -149,3 +149,78 @@ func TestRemoveFileRecursive(t *testing.T) {
 		t.Fatalf("expected 1 file after delete, got %d", len(filesAfterRemove))
 	}
 }
+
+func writeTestSite(t *testing.T, files map[string]string) *WebsiteConfig {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatalf("failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write test file %s: %v", name, err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Join(root, DefaultOutputDir), os.ModePerm); err != nil {
+		t.Fatalf("failed to create output directory: %v", err)
+	}
+
+	return &WebsiteConfig{
+		PagesDir:      filepath.Join(root, DefaultPagesDir),
+		DataDir:       filepath.Join(root, DefaultDataDir),
+		StaticDir:     filepath.Join(root, DefaultStaticDir),
+		CacheDir:      filepath.Join(root, DefaultCacheDir),
+		AssetsDir:     filepath.Join(root, DefaultAssetsDir),
+		DefaultLayout: DefaultLayout,
+		OutputDir:     filepath.Join(root, DefaultOutputDir),
+	}
+}
+
+func TestNewConfigServerOptions(t *testing.T) {
+	root := filepath.Dir(writeTestSite(t, map[string]string{
+		"pages/layouts/default.tmpl": ``,
+		"ditto.json":                 `{"server": {"trailingSlash": "remove"}}`,
+	}).PagesDir)
+
+	config, err := NewConfig(root)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if string(config.Server) != `{"trailingSlash": "remove"}` {
+		t.Errorf("expected the server block to be kept for the serve command, got %s", config.Server)
+	}
+	if config.MocksDir != filepath.Join(root, DefaultMocksDir) {
+		t.Errorf("expected the mocks directory in the project root, got %s", config.MocksDir)
+	}
+}
+
+func TestNewConfigReadsConfigFile(t *testing.T) {
+	root := filepath.Dir(writeTestSite(t, map[string]string{
+		"pages/layouts/default.tmpl": ``,
+		"ditto.json":                 `{"permalinks": {"blog": "/:section/:slug/"}}`,
+	}).PagesDir)
+
+	config, err := NewConfig(root)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if config.Permalinks["blog"] != "/:section/:slug/" {
+		t.Errorf("expected blog permalink to be read from config, got %v", config.Permalinks)
+	}
+}
+
+func TestNewConfigWithoutOutputDir(t *testing.T) {
+	config := writeTestSite(t, map[string]string{
+		"pages/layouts/default.tmpl": ``,
+	})
+	if err := os.Remove(config.OutputDir); err != nil {
+		t.Fatal(err)
+	}
+
+	// serving from memory never writes the output directory
+	if _, err := NewConfig(filepath.Dir(config.PagesDir)); err != nil {
+		t.Fatalf("expected no error without an output directory, got %v", err)
+	}
+}
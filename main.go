@@ -1,41 +1,258 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
+	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
 
+	checks "github.com/eastcitysoftware/ditto/internal/check"
+	"github.com/eastcitysoftware/ditto/internal/highlight"
+	"github.com/eastcitysoftware/ditto/internal/memfs"
 	"github.com/eastcitysoftware/ditto/internal/server"
 	"github.com/eastcitysoftware/ditto/internal/watcher"
 	"github.com/eastcitysoftware/ditto/internal/website"
 )
 
-func main() {
-	// parse command line flags
-	root := flag.String("root", "", "root directory of the project")
-	port := flag.Int("port", 8080, "port to run the server on")
-	flag.Parse()
+const usage = `usage: ditto <command> [flags] [root]
+
+commands:
+  build      render the website to the output directory
+  serve      render the website, watch for changes and serve it locally
+  check      validate the website, "check links" reports broken internal
+             links in the output directory of the last build, "check
+             templates" reports undefined templates, unused partials and
+             layouts and fields that no page sets
+  highlight  print the stylesheet for highlighted code, -theme selects one
+             of the built in themes
 
+running ditto with only a root directory is the same as "ditto serve".
+`
+
+func main() {
 	// show usage if no arguments are provided
 	if len(os.Args) <= 1 {
-		flag.Usage()
+		fmt.Fprint(os.Stderr, usage)
 		os.Exit(0)
 	}
 
-	// if there is a single argument and no root is specified, use the first
-	// argument as the root
-	if len(os.Args) == 2 {
-		if *root == "" {
-			*root = os.Args[1]
-		}
+	switch os.Args[1] {
+	case "build":
+		build(os.Args[2:])
+	case "serve":
+		serve(os.Args[2:])
+	case "check":
+		check(os.Args[2:])
+	case "highlight":
+		highlightCSS(os.Args[2:])
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(os.Stderr, usage)
+	default:
+		// without a command the arguments are passed to serve
+		serve(os.Args[1:])
+	}
+}
+
+func build(args []string) {
+	flags := flag.NewFlagSet("build", flag.ExitOnError)
+	root := flags.String("root", "", "root directory of the project")
+	flags.Parse(args)
+
+	config := newConfig(*root, flags)
+	checkOutputDir(config)
+	site := loadAndRender(config)
+	log.Println("built website with", len(site.Pages), "pages")
+}
+
+func serve(args []string) {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	root := flags.String("root", "", "root directory of the project")
+	port := flags.Int("port", 8080, "port to run the server on")
+	drafts := flags.Bool("drafts", false, "include draft, future and expired pages")
+	minify := flags.Bool("minify", false, "minify pages and static files")
+	memory := flags.Bool("memory", false, "serve from memory without writing the output directory")
+	flags.Parse(args)
+
+	config := newConfig(*root, flags)
+	config.IncludeDrafts = *drafts
+	options := serverOptions(config)
+
+	// output is left readable while developing unless asked for, and bundles
+	// keep their names so they can be rebuilt without re-rendering pages
+	config.Minify = *minify
+	config.Fingerprint = false
+
+	// only build writes the output directory when serving from memory
+	files := http.FileSystem(http.Dir(config.OutputDir))
+	if *memory {
+		config.Memory = memfs.New()
+		files = config.Memory
+	} else {
+		checkOutputDir(config)
+	}
+	loadAndRender(config)
+
+	log.Println("watching for changes in", config.PagesDir)
+	go watcher.WatchDirectory(
+		config.PagesDir,
+		[]string{website.TmplExtension, ".json"},
+		func(fileInfo *watcher.FileInfo) error {
+			log.Printf("File changed: %s", fileInfo.Path)
+			return reloadAndRender(config, fileInfo.Path)
+		})
+
+	// data, static files, translations and themes can affect any page, so a
+	// change re-renders the whole site
+	for _, dir := range append([]string{config.DataDir, config.StaticDir, config.I18nDir}, config.Themes...) {
+		go watcher.WatchDirectory(
+			dir,
+			nil,
+			func(fileInfo *watcher.FileInfo) error {
+				log.Printf("File changed: %s", fileInfo.Path)
+				return reloadAndRender(config, "")
+			})
+	}
+
+	// bundles are rebuilt on their own, pages link to them by a fixed name
+	go watcher.WatchDirectory(
+		config.AssetsDir,
+		nil,
+		func(fileInfo *watcher.FileInfo) error {
+			log.Printf("File changed: %s", fileInfo.Path)
+			return website.RenderBundles(config)
+		})
+
+	// start the development server
+	log.Println("starting development server on port", *port)
+	err := server.StartDevelopmentServer(*port, files, options)
+	if err != nil {
+		log.Fatalf("failed to start server: %v", err)
+	} else {
+		log.Print("gracefully stopped the server")
+	}
+}
+
+func check(args []string) {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch args[0] {
+	case "links":
+		checkLinks(args[1:])
+	case "templates":
+		checkTemplates(args[1:])
+	default:
+		log.Fatalf("unknown check %q", args[0])
 	}
+}
 
-	// create the website config
-	config, err := website.NewConfig(*root)
+func checkLinks(args []string) {
+	flags := flag.NewFlagSet("check links", flag.ExitOnError)
+	root := flags.String("root", "", "root directory of the project")
+	flags.Parse(args)
+
+	config := newConfig(*root, flags)
+	checkOutputDir(config)
+	site, err := website.Load(config)
+	if err != nil {
+		log.Fatalf("failed to load website: %v", err)
+	}
+
+	// map rendered files back to the templates that produced them
+	templates := map[string]string{}
+	for _, page := range site.Pages {
+		templates[filepath.ToSlash(page.Name)] = page.InputPath
+	}
+
+	broken, err := checks.CheckLinks(config.OutputDir, templates)
+	if err != nil {
+		log.Fatalf("failed to check links: %v", err)
+	}
+	for _, link := range broken {
+		fmt.Println(link)
+	}
+	if len(broken) > 0 {
+		log.Fatalf("found %d broken links", len(broken))
+	}
+	log.Println("no broken links found")
+}
+
+func checkTemplates(args []string) {
+	flags := flag.NewFlagSet("check templates", flag.ExitOnError)
+	root := flags.String("root", "", "root directory of the project")
+	flags.Parse(args)
+
+	config := newConfig(*root, flags)
+	site, err := website.Load(config)
+	if err != nil {
+		log.Fatalf("failed to load website: %v", err)
+	}
+
+	issues, err := checks.CheckTemplates(site)
+	if err != nil {
+		log.Fatalf("failed to check templates: %v", err)
+	}
+	for _, issue := range issues {
+		fmt.Println(issue)
+	}
+	if len(issues) > 0 {
+		log.Fatalf("found %d template issues", len(issues))
+	}
+	log.Println("no template issues found")
+}
+
+func highlightCSS(args []string) {
+	flags := flag.NewFlagSet("highlight", flag.ExitOnError)
+	theme := flags.String("theme", "github", "highlight theme, one of "+strings.Join(highlight.Themes(), ", "))
+	flags.Parse(args)
+
+	css, err := highlight.CSS(*theme)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Print(css)
+}
+
+func newConfig(root string, flags *flag.FlagSet) *website.WebsiteConfig {
+	// if no root is specified, use the first positional argument as the root
+	if root == "" {
+		root = flags.Arg(0)
+	}
+
+	config, err := website.NewConfig(root)
 	if err != nil {
 		log.Fatalf("failed to create page config: %v", err)
 	}
+	return config
+}
 
+// checkOutputDir stops commands that write or read the output directory
+// when it does not exist
+func checkOutputDir(config *website.WebsiteConfig) {
+	if _, err := os.Stat(config.OutputDir); err != nil {
+		log.Fatal("public directory does not exist")
+	}
+}
+
+// serverOptions reads the server block of the project config file
+func serverOptions(config *website.WebsiteConfig) server.Options {
+	options := server.Options{}
+	if len(config.Server) > 0 {
+		if err := json.Unmarshal(config.Server, &options); err != nil {
+			log.Fatalf("failed to read server options: %v", err)
+		}
+	}
+	options.MocksDir = config.MocksDir
+	return options
+}
+
+func loadAndRender(config *website.WebsiteConfig) *website.Website {
 	// load the website from disk
 	site, err := website.Load(config)
 	if err != nil {
@@ -44,28 +261,34 @@ func main() {
 	log.Println("loaded website with", len(site.Pages), "pages")
 
 	// render the website to disk
-	log.Println("rendering pages to", site.OutputDir)
+	if config.Memory != nil {
+		log.Println("rendering pages to memory")
+	} else {
+		log.Println("rendering pages to", site.OutputDir)
+	}
 	err = website.Render(site, "")
 	if err != nil {
 		log.Fatalf("rendering pages failed with %v", err)
 	}
+	return site
+}
 
-	log.Println("watching for changes in", config.PagesDir)
-	go watcher.WatchDirectory(
-		config.PagesDir,
-		[]string{website.TmplExtension},
-		func(fileInfo *watcher.FileInfo) error {
-			log.Printf("File changed: %s", fileInfo.Path)
-			website.Render(site, fileInfo.Path)
-			return nil
-		})
-
-	// start the development server
-	log.Println("starting development server on port", *port)
-	err = server.StartDevelopmentServer(*port, config.OutputDir)
+func reloadAndRender(config *website.WebsiteConfig, fileToRender string) error {
+	// reload so that frontmatter and data changes are picked up
+	site, err := website.Load(config)
 	if err != nil {
-		log.Fatalf("failed to start server: %v", err)
-	} else {
-		log.Print("gracefully stopped the server")
+		log.Printf("failed to load website: %v", err)
+		return err
+	}
+
+	// layouts, partials and defaults files are not pages but can affect any
+	// page, so a change to them re-renders the whole site
+	isPage := false
+	for _, page := range site.Pages {
+		isPage = isPage || page.InputPath == fileToRender
+	}
+	if !isPage {
+		fileToRender = ""
 	}
+	return website.Render(site, fileToRender)
 }